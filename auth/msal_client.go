@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/jrrdcnnlly/core/config"
 	"github.com/jrrdcnnlly/core/sessions"
 
 	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/confidential"
@@ -15,11 +16,13 @@ type MSALClient struct {
 	confidential.Client
 	authority    string
 	clientId     string
-	clientSecret string
+	clientSecret config.Secret[string]
 	scopes       []string
 	redirectURL  *url.URL
 }
 
+// Create a new MSALClient authenticating with a plain client secret.
+// Prefer NewMSALClientSecret(), which keeps the secret redacted, e.g. when resolved with AsSecret().
 func NewMSALClient(
 	authority string,
 	clientId string,
@@ -27,14 +30,25 @@ func NewMSALClient(
 	scopes []string,
 	redirectURL *url.URL,
 ) (*MSALClient, error) {
-	cred, err := confidential.NewCredFromSecret(clientSecret)
+	return NewMSALClientSecret(authority, clientId, config.NewSecret(clientSecret), scopes, redirectURL)
+}
+
+// Create a new MSALClient authenticating with a redacted client secret.
+func NewMSALClientSecret(
+	authority string,
+	clientId string,
+	clientSecret config.Secret[string],
+	scopes []string,
+	redirectURL *url.URL,
+) (*MSALClient, error) {
+	cred, err := confidential.NewCredFromSecret(clientSecret.Reveal())
 	if err != nil {
-		return nil, fmt.Errorf("NewMSALClient: %w", err)
+		return nil, fmt.Errorf("NewMSALClientSecret: %w", err)
 	}
 
 	client, err := confidential.New(authority, clientId, cred)
 	if err != nil {
-		return nil, fmt.Errorf("NewMSALClient: %w", err)
+		return nil, fmt.Errorf("NewMSALClientSecret: %w", err)
 	}
 
 	msal := &MSALClient{
@@ -60,7 +74,6 @@ func (c *MSALClient) refreshToken(ctx context.Context, s *sessions.Session) erro
 		return fmt.Errorf("MSALClient.refreshToken(): failed to refresh token: %w", err)
 	}
 
-	s.Expires = result.ExpiresOn
 	s.UserID = result.Account.HomeAccountID
 	s.Username = result.Account.PreferredUsername
 	return nil
@@ -87,7 +100,6 @@ func (c *MSALClient) acquireToken(ctx context.Context, code string, s *sessions.
 		return fmt.Errorf("MSALClient.acquireToken(): %w", err)
 	}
 
-	s.Expires = result.ExpiresOn
 	s.UserID = result.Account.HomeAccountID
 	s.Username = result.Account.PreferredUsername
 	return nil
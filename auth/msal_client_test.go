@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/jrrdcnnlly/core/config"
+)
+
+func TestNewMSALClient(t *testing.T) {
+	redirectURL, err := url.Parse("https://app.example.com/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authority := "https://login.microsoftonline.com/tenant"
+
+	plain, err := NewMSALClient(authority, "client", "hunter2", nil, redirectURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := NewMSALClientSecret(authority, "client", config.NewSecret("hunter2"), nil, redirectURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, client := range []*MSALClient{plain, secret} {
+		if actual := client.clientSecret.Reveal(); actual != "hunter2" {
+			t.Errorf("MSALClient.clientSecret = %q; expect %q", actual, "hunter2")
+		}
+	}
+}
@@ -36,6 +36,8 @@ func MSALHandler(client *MSALClient) http.Handler {
 			return
 		}
 
+		// Move the authenticated session to a new ID to prevent session fixation.
+		session.Regenerate()
 		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 	})
 }
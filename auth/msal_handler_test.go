@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jrrdcnnlly/core/sessions"
+)
+
+// Send a login callback through the session middleware and MSALHandler,
+// returning the session as saved in the store.
+func login(t *testing.T, store sessions.SessionStore, handler http.Handler, cookies ...*http.Cookie) (*http.Response, *sessions.Session) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/callback?code=code", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	res := rec.Result()
+	if res.StatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("status = %d; expect %d", res.StatusCode, http.StatusTemporaryRedirect)
+	}
+	if len(res.Cookies()) == 0 {
+		t.Fatalf("response has no cookies; expect a session cookie")
+	}
+	session, err := store.Read(res.Cookies()[0].Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, session
+}
+
+func TestMSALHandler_Expires(t *testing.T) {
+	store := sessions.NewMemoryStore(sessions.WithLifetime(8 * time.Hour))
+	handler := sessions.Middleware(store)(MSALHandler(newTestMSALClient(t)))
+
+	_, session := login(t, store, handler)
+	if session.Username != "alice@example.com" {
+		t.Errorf("Session.Username = %q; expect %q", session.Username, "alice@example.com")
+	}
+	// The store's lifetime applies, not the one hour lifetime of the access token.
+	if expect := session.Created.Add(8 * time.Hour); !session.Expires.Equal(expect) {
+		t.Errorf("Session.Expires = %v; expect %v", session.Expires, expect)
+	}
+}
+
+func TestMSALHandler_Regenerate(t *testing.T) {
+	store := sessions.NewMemoryStore()
+	handler := sessions.Middleware(store)(MSALHandler(newTestMSALClient(t)))
+
+	// Obtain a session before logging in, as an attacker planting a session ID would.
+	rec := httptest.NewRecorder()
+	sessions.Middleware(store)(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	before := rec.Result().Cookies()
+	if len(before) == 0 {
+		t.Fatalf("response has no cookies; expect a session cookie")
+	}
+
+	res, session := login(t, store, handler, before[0])
+	after := res.Cookies()[0]
+	if after.Value == before[0].Value {
+		t.Fatalf("cookie value = %q; expect a new session ID after login", after.Value)
+	}
+	if session.Username != "alice@example.com" {
+		t.Errorf("Session.Username = %q; expect %q", session.Username, "alice@example.com")
+	}
+	if _, err := store.Read(before[0].Value); err == nil {
+		t.Errorf("MemoryStore.Read() error = nil; expect the pre-login session ID to be deleted")
+	}
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrrdcnnlly/core/config"
+	"github.com/jrrdcnnlly/core/sessions"
+
+	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/confidential"
+)
+
+// Create an MSALClient backed by a stand-in identity provider.
+func newTestMSALClient(t *testing.T) *MSALClient {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := server.URL + "/tenant"
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/.well-known/openid-configuration"):
+			json.NewEncoder(w).Encode(map[string]string{
+				"authorization_endpoint": tenant + "/oauth2/v2.0/authorize",
+				"token_endpoint":         tenant + "/oauth2/v2.0/token",
+				"issuer":                 tenant + "/v2.0",
+			})
+		case strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token"):
+			json.NewEncoder(w).Encode(testTokenResponse(tenant))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	secret := config.NewSecret("secret")
+	cred, err := confidential.NewCredFromSecret(secret.Reveal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := confidential.New(
+		server.URL+"/tenant",
+		"client",
+		cred,
+		confidential.WithHTTPClient(server.Client()),
+		confidential.WithInstanceDiscovery(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redirectURL, _ := url.Parse("https://app.example.com/callback")
+
+	return &MSALClient{
+		Client:       client,
+		authority:    server.URL + "/tenant",
+		clientId:     "client",
+		clientSecret: secret,
+		scopes:       []string{"User.Read"},
+		redirectURL:  redirectURL,
+	}
+}
+
+// Create a token endpoint response for alice@example.com, valid for one hour.
+func testTokenResponse(tenant string) map[string]any {
+	encode := func(value any) string {
+		data, _ := json.Marshal(value)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	now := time.Now().Unix()
+	idToken := encode(map[string]string{"alg": "none"}) + "." + encode(map[string]any{
+		"aud":                "client",
+		"iss":                tenant + "/v2.0",
+		"sub":                "sub",
+		"oid":                "uid",
+		"tid":                "utid",
+		"preferred_username": "alice@example.com",
+		"iat":                now,
+		"nbf":                now,
+		"exp":                now + 3600,
+	}) + "."
+	return map[string]any{
+		"token_type":    "Bearer",
+		"scope":         "User.Read",
+		"expires_in":    3600,
+		"access_token":  "access",
+		"refresh_token": "refresh",
+		"id_token":      idToken,
+		"client_info":   encode(map[string]string{"uid": "uid", "utid": "utid"}),
+	}
+}
+
+func TestMSALMiddleware_Sessions(t *testing.T) {
+	client := newTestMSALClient(t)
+
+	var reached bool
+	protected := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	})
+	handler := sessions.Middleware(sessions.NewMemoryStore())(MSALMiddleware(client)(protected))
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// An unauthenticated session is redirected to the login page, rather than failing to find a session.
+	if res.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("status = %d; expect %d", res.Code, http.StatusTemporaryRedirect)
+	}
+	location, err := url.Parse(res.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(location.Path, "/oauth2/v2.0/authorize") {
+		t.Errorf("Location = %q; expect the authorize endpoint", location)
+	}
+	if location.Query().Get("client_id") != "client" {
+		t.Errorf("Location client_id = %q; expect %q", location.Query().Get("client_id"), "client")
+	}
+	if reached {
+		t.Errorf("protected handler was called for an unauthenticated session")
+	}
+
+	// The redirect carries the session cookie.
+	if len(res.Result().Cookies()) == 0 {
+		t.Errorf("response has no cookies; expect a session cookie")
+	}
+}
@@ -0,0 +1,85 @@
+// Command config-encrypt encrypts configuration values for use with config.Decrypt().
+//
+// Usage:
+//
+//	config-encrypt [-keyring path] [-key id] [value]
+//	config-encrypt -generate id
+//
+// The keyring is read from the file given by -keyring, or the CONFIG_KEYS environment variable.
+// If no value is given it is read from standard input.
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jrrdcnnlly/core/config"
+)
+
+func main() {
+	keyringPath := flag.String("keyring", "", "path of the keyring file, defaults to $"+config.KeyringVariable)
+	keyID := flag.String("key", "", "id of the key to encrypt with, defaults to the primary key")
+	generate := flag.String("generate", "", "print a new keyring line for the given key id and exit")
+	flag.Parse()
+
+	if *generate != "" {
+		key, err := config.GenerateKey()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config-encrypt: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s=%s\n", *generate, base64.StdEncoding.EncodeToString(key))
+		return
+	}
+
+	if err := run(*keyringPath, *keyID, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "config-encrypt: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// Encrypt the value in args, or standard input, and print the result.
+func run(keyringPath string, keyID string, args []string) error {
+	keyring, err := loadKeyring(keyringPath)
+	if err != nil {
+		return err
+	}
+	if keyID != "" {
+		if err := keyring.SetPrimary(keyID); err != nil {
+			return err
+		}
+	}
+
+	var value string
+	switch len(args) {
+	case 0:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	case 1:
+		value = args[0]
+	default:
+		return fmt.Errorf("expected a single value, got %d", len(args))
+	}
+
+	encrypted, err := keyring.Encrypt(value)
+	if err != nil {
+		return err
+	}
+	fmt.Println(encrypted)
+	return nil
+}
+
+// Load the keyring from a file, or the environment if no path is given.
+func loadKeyring(path string) (*config.Keyring, error) {
+	if path != "" {
+		return config.LoadKeyring(path)
+	}
+	return config.KeyringFromEnvironment(config.OS, config.KeyringVariable)
+}
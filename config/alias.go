@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Returned when a deprecated key is used after its cutoff.
+var ErrDeprecatedKey = errors.New("deprecated key")
+
+// Deprecated name of a setting.
+type Alias struct {
+	Old    string    // Deprecated key.
+	New    string    // Replacement key.
+	Cutoff time.Time // Using the old key after the cutoff is an error. Zero for no cutoff.
+}
+
+// Environment that resolves renamed keys from their deprecated aliases.
+// Create with Aliased().
+type AliasedEnvironment struct {
+	env     Environment
+	aliases map[string][]Alias // Aliases by replacement key.
+	warned  sync.Map           // Deprecated keys that have been logged.
+	expired sync.Map           // Deprecated keys past their cutoff that have been logged.
+	now     func() time.Time
+}
+
+// Create an AliasedEnvironment.
+func Aliased(env Environment, aliases ...Alias) *AliasedEnvironment {
+	a := &AliasedEnvironment{
+		env:     env,
+		aliases: map[string][]Alias{},
+		now:     time.Now,
+	}
+	for _, alias := range aliases {
+		a.aliases[alias.New] = append(a.aliases[alias.New], alias)
+	}
+	return a
+}
+
+// Implement the Environment interface.
+// The replacement key takes precedence over its aliases.
+// Using an alias logs a warning naming the replacement, once per alias.
+// Aliases used after their cutoff are reported as not set, and logged as errors once per alias.
+func (a *AliasedEnvironment) LookupEnv(key string) (string, bool) {
+	if value, ok := a.env.LookupEnv(key); ok {
+		return value, true
+	}
+	alias, value, ok := a.lookupAlias(key)
+	if !ok {
+		return "", false
+	}
+
+	if err := a.checkCutoff(alias); err != nil {
+		if _, logged := a.expired.LoadOrStore(alias.Old, struct{}{}); !logged {
+			slog.Error(
+				"deprecated configuration key is no longer supported",
+				slog.String("key", qualifyKey(a.env, alias.Old)),
+				slog.String("replacement", qualifyKey(a.env, alias.New)),
+				slog.Any("err", err),
+			)
+		}
+		return "", false
+	}
+
+	if _, warned := a.warned.LoadOrStore(alias.Old, struct{}{}); !warned {
+		attrs := []any{
+			slog.String("key", qualifyKey(a.env, alias.Old)),
+			slog.String("replacement", qualifyKey(a.env, alias.New)),
+		}
+		if !alias.Cutoff.IsZero() {
+			attrs = append(attrs, slog.Time("cutoff", alias.Cutoff))
+		}
+		slog.Warn("deprecated configuration key is in use", attrs...)
+	}
+	return value, true
+}
+
+// Check that no deprecated key is in use after its cutoff.
+func (a *AliasedEnvironment) Check() error {
+	var errs []error
+	for key := range a.aliases {
+		if _, ok := a.env.LookupEnv(key); ok {
+			continue
+		}
+		if alias, _, ok := a.lookupAlias(key); ok {
+			if err := a.checkCutoff(alias); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Return the kind of source a key is read from.
+func (a *AliasedEnvironment) SourceOf(key string) string {
+	return sourceOf(a.env, a.resolvedKey(key))
+}
+
+// Does the environment hold key, or its alias, as a secret?
+func (a *AliasedEnvironment) IsSecret(key string) bool {
+	return isSecretKey(a.env, a.resolvedKey(key))
+}
+
+// Return the name a key is stored under, which is the alias if the key is only set by its alias.
+func (a *AliasedEnvironment) QualifyKey(key string) string {
+	return qualifyKey(a.env, a.resolvedKey(key))
+}
+
+// Return the first alias of key that is set, and its value.
+func (a *AliasedEnvironment) lookupAlias(key string) (Alias, string, bool) {
+	for _, alias := range a.aliases[key] {
+		if value, ok := a.env.LookupEnv(alias.Old); ok {
+			return alias, value, true
+		}
+	}
+	return Alias{}, "", false
+}
+
+// Return the key the value of key is read from.
+func (a *AliasedEnvironment) resolvedKey(key string) string {
+	if _, ok := a.env.LookupEnv(key); ok {
+		return key
+	}
+	if alias, _, ok := a.lookupAlias(key); ok {
+		return alias.Old
+	}
+	return key
+}
+
+// Return an error if the alias cutoff has passed.
+func (a *AliasedEnvironment) checkCutoff(alias Alias) error {
+	if alias.Cutoff.IsZero() || a.now().Before(alias.Cutoff) {
+		return nil
+	}
+	return fmt.Errorf(
+		"%w %s: replaced by %s since %s",
+		ErrDeprecatedKey,
+		qualifyKey(a.env, alias.Old),
+		qualifyKey(a.env, alias.New),
+		alias.Cutoff.Format(time.DateOnly),
+	)
+}
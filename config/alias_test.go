@@ -0,0 +1,75 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAliasedEnvironment_LookupEnv(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(previous)
+
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	env := Aliased(
+		MapEnvironment{
+			"PORT":       "5000",
+			"HOST":       "localhost",
+			"HTTP_HOST":  "example.com",
+			"SECRET_KEY": "hunter2",
+		},
+		Alias{Old: "PORT", New: "HTTP_PORT"},
+		Alias{Old: "HOST", New: "HTTP_HOST"},
+		Alias{Old: "SECRET_KEY", New: "CLIENT_SECRET", Cutoff: now.Add(-time.Hour)},
+	)
+	env.now = func() time.Time { return now }
+
+	type Test struct {
+		name      string
+		key       string
+		expect    string
+		expectOk  bool
+		expectKey string
+	}
+
+	tests := []Test{
+		{name: "LookupEnv() should resolve aliases", key: "HTTP_PORT", expect: "5000", expectOk: true, expectKey: "PORT"},
+		{name: "LookupEnv() should prefer the replacement", key: "HTTP_HOST", expect: "example.com", expectOk: true, expectKey: "HTTP_HOST"},
+		{name: "LookupEnv() should reject aliases after the cutoff", key: "CLIENT_SECRET", expect: "", expectOk: false, expectKey: "SECRET_KEY"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			variable := EnvironmentVariable(test.key).In(env)
+			result, ok := variable.Lookup()
+			if result != test.expect || ok != test.expectOk {
+				t.Errorf("AliasedEnvironment.LookupEnv() = %q, %v; expect %q, %v", result, ok, test.expect, test.expectOk)
+			}
+			if key := variable.Key(); key != test.expectKey {
+				t.Errorf("Variable.Key() = %q; expect %q", key, test.expectKey)
+			}
+		})
+	}
+
+	// Repeated lookups only log once.
+	env.LookupEnv("HTTP_PORT")
+	env.LookupEnv("CLIENT_SECRET")
+	if count := strings.Count(logs.String(), "deprecated configuration key is in use"); count != 1 {
+		t.Errorf("logged %d warnings; expect 1:\n%s", count, logs.String())
+	}
+	if count := strings.Count(logs.String(), "deprecated configuration key is no longer supported"); count != 1 {
+		t.Errorf("logged %d errors; expect 1:\n%s", count, logs.String())
+	}
+	if !strings.Contains(logs.String(), "replacement=HTTP_PORT") {
+		t.Errorf("logs = %q; expect the replacement to be named", logs.String())
+	}
+
+	if err := env.Check(); !errors.Is(err, ErrDeprecatedKey) {
+		t.Errorf("AliasedEnvironment.Check() = %v; expect %v", err, ErrDeprecatedKey)
+	}
+}
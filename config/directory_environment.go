@@ -0,0 +1,187 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Name of the symlink Kubernetes atomically swaps when a mounted volume is updated.
+const dataLink string = "..data"
+
+// DirectoryEnvironment configuration.
+type directoryEnvironmentConfig struct {
+	keyMapper func(name string) string
+}
+
+// DirectoryEnvironment option.
+type DirectoryEnvironmentOption func(cfg *directoryEnvironmentConfig)
+
+// Environment read from a directory containing one file per key,
+// e.g. a mounted Kubernetes ConfigMap or Secret.
+// Create with LoadDirectory().
+type DirectoryEnvironment struct {
+	dir       string
+	keyMapper func(name string) string
+	values    map[string]string // Current values by key.
+	version   string            // Target of the ..data symlink when values were loaded.
+	mutex     sync.RWMutex      // Sync access to values and version.
+}
+
+// Optional function mapping file names to keys, e.g. EnvironmentKey.
+// Defaults to using file names as keys.
+func WithKeyMapper(mapper func(name string) string) DirectoryEnvironmentOption {
+	return func(cfg *directoryEnvironmentConfig) {
+		cfg.keyMapper = mapper
+	}
+}
+
+// Map a file name such as "http-port" or "http.port" to an environment style key, "HTTP_PORT".
+func EnvironmentKey(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// Load a DirectoryEnvironment from dir.
+func LoadDirectory(dir string, options ...DirectoryEnvironmentOption) (*DirectoryEnvironment, error) {
+	// Init default config.
+	cfg := &directoryEnvironmentConfig{
+		keyMapper: func(name string) string { return name },
+	}
+	// Apply options to config.
+	for _, option := range options {
+		option(cfg)
+	}
+
+	d := &DirectoryEnvironment{
+		dir:       dir,
+		keyMapper: cfg.keyMapper,
+	}
+	if _, err := d.Refresh(); err != nil {
+		return nil, fmt.Errorf("LoadDirectory: %w", err)
+	}
+	return d, nil
+}
+
+// Read the values from the directory again.
+// Returns true if the values have changed.
+func (d *DirectoryEnvironment) Refresh() (bool, error) {
+	version := d.currentVersion()
+	values, err := d.read()
+	if err != nil {
+		return false, fmt.Errorf("DirectoryEnvironment.Refresh(): %w", err)
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	changed := !maps.Equal(d.values, values)
+	d.values = values
+	d.version = version
+	return changed, nil
+}
+
+// Check the directory every interval and reload the reloaders when its values change.
+// Kubernetes volumes are only read again after the ..data symlink has been swapped,
+// other directories are read every interval.
+// Stops watching when the context is done.
+func (d *DirectoryEnvironment) Watch(ctx context.Context, interval time.Duration, reloaders ...Reloader) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !d.swapped() {
+					continue
+				}
+				changed, err := d.Refresh()
+				if err != nil {
+					slog.Warn(
+						"failed to refresh configuration directory",
+						slog.String("dir", d.dir),
+						slog.Any("err", err),
+					)
+					continue
+				}
+				if changed {
+					reloadAll(reloaders)
+				}
+			}
+		}
+	}()
+}
+
+// Implement the Environment interface.
+func (d *DirectoryEnvironment) LookupEnv(key string) (string, bool) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	value, ok := d.values[key]
+	return value, ok
+}
+
+// Return the kind of source a key is read from.
+func (d *DirectoryEnvironment) SourceOf(key string) string {
+	return "directory:" + d.dir
+}
+
+// Has the directory changed since the values were loaded?
+// Always true for directories without a ..data symlink.
+func (d *DirectoryEnvironment) swapped() bool {
+	version := d.currentVersion()
+	if version == "" {
+		return true
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	return version != d.version
+}
+
+// Return the target of the ..data symlink, or an empty string if there is none.
+func (d *DirectoryEnvironment) currentVersion() string {
+	target, err := os.Readlink(filepath.Join(d.dir, dataLink))
+	if err != nil {
+		return ""
+	}
+	return target
+}
+
+// Read every file in the directory.
+// Hidden files, such as Kubernetes' ..data, and directories are skipped.
+func (d *DirectoryEnvironment) read() (map[string]string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(d.dir, name)
+		// Stat follows the symlinks Kubernetes creates for each key.
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		value, err := readSecretFile(path)
+		if err != nil {
+			continue
+		}
+		values[d.keyMapper(name)] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Write a Kubernetes style volume revision and atomically swap the ..data symlink to it.
+func writeVolume(t *testing.T, dir string, revision string, files map[string]string) {
+	t.Helper()
+
+	revisionDir := filepath.Join(dir, "..rev_"+revision)
+	if err := os.Mkdir(revisionDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(revisionDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); err != nil {
+			if err := os.Symlink(filepath.Join(dataLink, name), link); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	temp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(filepath.Base(revisionDir), temp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(temp, filepath.Join(dir, dataLink)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeVolume(t, dir, "1", map[string]string{
+		"http-port":     "5000\n",
+		"client-secret": "hunter2\r\n",
+	})
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := LoadDirectory(dir, WithKeyMapper(EnvironmentKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Test struct {
+		key      string
+		expect   string
+		expectOk bool
+	}
+
+	tests := []Test{
+		{key: "HTTP_PORT", expect: "5000", expectOk: true},
+		{key: "CLIENT_SECRET", expect: "hunter2", expectOk: true},
+		{key: "NESTED", expect: "", expectOk: false},
+		{key: "..DATA", expect: "", expectOk: false},
+	}
+
+	for _, test := range tests {
+		t.Run("LookupEnv(\""+test.key+"\")", func(t *testing.T) {
+			result, ok := env.LookupEnv(test.key)
+			if result != test.expect || ok != test.expectOk {
+				t.Errorf("DirectoryEnvironment.LookupEnv() = %q, %v; expect %q, %v", result, ok, test.expect, test.expectOk)
+			}
+		})
+	}
+}
+
+func TestDirectoryEnvironment_Watch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeVolume(t, dir, "1", map[string]string{"HTTP_PORT": "5000\n"})
+
+	env, err := LoadDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setting := NewLiveSetting(ConvInt(EnvironmentVariable("HTTP_PORT").In(env)))
+	reloader := make(testReloader, 1)
+	env.Watch(t.Context(), 10*time.Millisecond, setting, reloader)
+
+	writeVolume(t, dir, "2", map[string]string{"HTTP_PORT": "6000\n"})
+	reloader.wait(t)
+	if result := setting.Get(); result != 6000 {
+		t.Errorf("LiveSetting.Get() = %d; expect %d", result, 6000)
+	}
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Prefix identifying an encrypted value.
+// Encrypted values have the form "enc:<key id>:<base64 nonce and ciphertext>".
+const EncryptedPrefix string = "enc:"
+
+// Environment variable holding a keyring, e.g. "2025=<base64 key>,2024=<base64 key>".
+const KeyringVariable string = "CONFIG_KEYS"
+
+var (
+	// Returned when a value is encrypted with a key that is not in the keyring.
+	ErrUnknownKey = errors.New("unknown key")
+	// Returned when an encrypted value is malformed or fails authentication.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// Set of AES-GCM keys used to decrypt configuration values, by key ID.
+// The primary key is used to encrypt new values, the others remain available for decryption
+// so keys can be rotated.
+// Create with NewKeyring(), LoadKeyring() or KeyringFromEnvironment().
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// Create a new empty Keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{
+		keys: map[string]cipher.AEAD{},
+	}
+}
+
+// Generate a new random 256 bit key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("GenerateKey: %w", err)
+	}
+	return key, nil
+}
+
+// Load a keyring from a file of "id=<base64 key>" lines.
+// The first key in the file is the primary key.
+func LoadKeyring(path string) (*Keyring, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadKeyring: %w", err)
+	}
+	defer file.Close()
+
+	keyring := NewKeyring()
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, key, err := parseEnvLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("LoadKeyring: %s:%d: %w", path, number, err)
+		}
+		if err := keyring.addEncoded(id, key); err != nil {
+			return nil, fmt.Errorf("LoadKeyring: %s:%d: %w", path, number, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("LoadKeyring: %w", err)
+	}
+	return keyring, nil
+}
+
+// Load a keyring from a comma separated list of "id=<base64 key>" pairs in the variable key.
+// The first key in the list is the primary key.
+func KeyringFromEnvironment(env Environment, key string) (*Keyring, error) {
+	value, ok := env.LookupEnv(key)
+	if !ok {
+		return nil, fmt.Errorf("KeyringFromEnvironment: %s is not set", qualifyKey(env, key))
+	}
+
+	keyring := NewKeyring()
+	for pair := range strings.SplitSeq(value, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("KeyringFromEnvironment: expected id=key in %s", qualifyKey(env, key))
+		}
+		if err := keyring.addEncoded(id, encoded); err != nil {
+			return nil, fmt.Errorf("KeyringFromEnvironment: %w", err)
+		}
+	}
+	return keyring, nil
+}
+
+// Add a key to the keyring. The first key added is the primary key.
+// Keys must be 16, 24 or 32 bytes long.
+func (k *Keyring) Add(id string, key []byte) error {
+	if id == "" || strings.Contains(id, ":") {
+		return fmt.Errorf("Keyring.Add(): invalid key id %q", id)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("Keyring.Add(): %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("Keyring.Add(): %w", err)
+	}
+	if k.primary == "" {
+		k.primary = id
+	}
+	k.keys[id] = aead
+	return nil
+}
+
+// Add a base64 encoded key to the keyring.
+func (k *Keyring) addEncoded(id string, encoded string) error {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid key %q: %w", id, err)
+	}
+	return k.Add(id, key)
+}
+
+// Make id the primary key used to encrypt new values.
+func (k *Keyring) SetPrimary(id string) error {
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("Keyring.SetPrimary(): %w %q", ErrUnknownKey, id)
+	}
+	k.primary = id
+	return nil
+}
+
+// Encrypt a value with the primary key.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	aead, ok := k.keys[k.primary]
+	if !ok {
+		return "", errors.New("Keyring.Encrypt(): keyring is empty")
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("Keyring.Encrypt(): %w", err)
+	}
+	// Authenticate the key ID so values cannot be moved between keys.
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.primary))
+	return EncryptedPrefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt a value. Values without the "enc:" prefix are returned unchanged.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	body, ok := strings.CutPrefix(value, EncryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+	id, encoded, ok := strings.Cut(body, ":")
+	if !ok {
+		return "", fmt.Errorf("%w: missing key id", ErrInvalidCiphertext)
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("%w: malformed value", ErrInvalidCiphertext)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidCiphertext, err)
+	}
+	return string(plaintext), nil
+}
+
+// Create a resolver that decrypts an "enc:" prefixed value resolved by another resolver.
+// Values that fail to decrypt are logged and leave the setting unset.
+// To decrypt values of other types, decrypt them before conversion with Decrypt().
+func Decrypted(keyring *Keyring, resolver Resolver[string]) Resolver[string] {
+	return func(s Setting[string]) Setting[string] {
+		if s.Set {
+			return s
+		}
+		inner := resolver(Setting[string]{})
+		if inner.Default != nil {
+			s.Default = inner.Default
+		}
+		if !inner.Set {
+			return s
+		}
+		value, err := keyring.Decrypt(inner.Value)
+		if err != nil {
+			slog.Warn(
+				"failed to decrypt configuration value",
+				slog.String("origin", inner.Origin.String()),
+				slog.Any("err", err),
+			)
+			return s
+		}
+		origin := inner.Origin
+		origin.Secret = true
+		return s.resolved(value, origin)
+	}
+}
+
+// Environment that decrypts "enc:" prefixed values of another environment.
+// Create with Decrypt().
+type DecryptedEnvironment struct {
+	env     Environment
+	keyring *Keyring
+}
+
+// Create a DecryptedEnvironment.
+func Decrypt(env Environment, keyring *Keyring) DecryptedEnvironment {
+	return DecryptedEnvironment{
+		env:     env,
+		keyring: keyring,
+	}
+}
+
+// Implement the Environment interface.
+// Values that fail to decrypt are logged and reported as not set.
+func (d DecryptedEnvironment) LookupEnv(key string) (string, bool) {
+	value, ok, err := d.Decrypt(key)
+	if err != nil {
+		slog.Warn(
+			"failed to decrypt configuration value",
+			slog.String("key", qualifyKey(d.env, key)),
+			slog.Any("err", err),
+		)
+		return "", false
+	}
+	return value, ok
+}
+
+// Return the decrypted value of key.
+// If the key is not set false is returned as the second value.
+func (d DecryptedEnvironment) Decrypt(key string) (string, bool, error) {
+	value, ok := d.env.LookupEnv(key)
+	if !ok {
+		return "", false, nil
+	}
+	plaintext, err := d.keyring.Decrypt(value)
+	if err != nil {
+		return "", true, fmt.Errorf("%s: %w", qualifyKey(d.env, key), err)
+	}
+	return plaintext, true, nil
+}
+
+// Check that the values of keys decrypt without errors.
+func (d DecryptedEnvironment) Check(keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		if _, _, err := d.Decrypt(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Return the kind of source a key is read from.
+func (d DecryptedEnvironment) SourceOf(key string) string {
+	return sourceOf(d.env, key)
+}
+
+// Is the value of key encrypted, or otherwise held as a secret?
+func (d DecryptedEnvironment) IsSecret(key string) bool {
+	value, _ := d.env.LookupEnv(key)
+	return strings.HasPrefix(value, EncryptedPrefix) || isSecretKey(d.env, key)
+}
+
+// Return the name a key is stored under.
+func (d DecryptedEnvironment) QualifyKey(key string) string {
+	return qualifyKey(d.env, key)
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Generate a key or fail the test.
+func generateTestKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKeyring_Decrypt(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := generateTestKey(t), generateTestKey(t)
+
+	previous := NewKeyring()
+	if err := previous.Add("2024", oldKey); err != nil {
+		t.Fatal(err)
+	}
+	old, err := previous.Encrypt("old secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate to a new primary key, keeping the old key for decryption.
+	keyring := NewKeyring()
+	if err := keyring.Add("2025", newKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Add("2024", oldKey); err != nil {
+		t.Fatal(err)
+	}
+	current, err := keyring.Encrypt("new secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(current, EncryptedPrefix+"2025:") {
+		t.Errorf("Keyring.Encrypt() = %q; expect prefix %q", current, EncryptedPrefix+"2025:")
+	}
+
+	tampered := current[:len(current)-4] + "AAA="
+
+	type Test struct {
+		name      string
+		value     string
+		expect    string
+		expectErr error
+	}
+
+	tests := []Test{
+		{name: "Decrypt() should decrypt with the primary key", value: current, expect: "new secret"},
+		{name: "Decrypt() should decrypt with rotated keys", value: old, expect: "old secret"},
+		{name: "Decrypt() should leave plain values", value: "plain", expect: "plain"},
+		{name: "Decrypt() should fail on unknown keys", value: EncryptedPrefix + "2023:AAAA", expectErr: ErrUnknownKey},
+		{name: "Decrypt() should fail on missing key ids", value: EncryptedPrefix + "AAAA", expectErr: ErrInvalidCiphertext},
+		{name: "Decrypt() should fail on tampered values", value: tampered, expectErr: ErrInvalidCiphertext},
+		{name: "Decrypt() should fail on moved values", value: strings.Replace(current, "2025", "2024", 1), expectErr: ErrInvalidCiphertext},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := keyring.Decrypt(test.value)
+			if !errors.Is(err, test.expectErr) {
+				t.Fatalf("Keyring.Decrypt() error = %v; expect %v", err, test.expectErr)
+			}
+			if result != test.expect {
+				t.Errorf("Keyring.Decrypt() = %q; expect %q", result, test.expect)
+			}
+		})
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	t.Parallel()
+
+	key := base64.StdEncoding.EncodeToString(generateTestKey(t))
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte("# Keys\n2025="+key+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fromFile, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromEnv, err := KeyringFromEnvironment(MapEnvironment{KeyringVariable: "2025=" + key}, KeyringVariable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := fromFile.Encrypt("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := fromEnv.Decrypt(encrypted)
+	if err != nil || result != "hunter2" {
+		t.Errorf("Keyring.Decrypt() = %q, %v; expect %q, nil", result, err, "hunter2")
+	}
+}
+
+func TestDecryptedEnvironment(t *testing.T) {
+	t.Parallel()
+
+	keyring := NewKeyring()
+	if err := keyring.Add("2025", generateTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := keyring.Encrypt("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := Decrypt(MapEnvironment{
+		"CLIENT_SECRET": encrypted,
+		"HTTP_HOST":     "localhost",
+		"BROKEN":        EncryptedPrefix + "2025:AAAA",
+	}, keyring)
+
+	secret := Setting[Secret[string]]{}.Resolve(
+		AsSecret(ConvString(NewSecretVariable("CLIENT_SECRET").In(env), false)),
+	)
+	if secret.Reveal() != "hunter2" {
+		t.Errorf("CLIENT_SECRET = %q; expect %q", secret.Reveal(), "hunter2")
+	}
+
+	host := Setting[string]{}.Resolve(ConvString(EnvironmentVariable("HTTP_HOST").In(env), false))
+	if host != "localhost" {
+		t.Errorf("HTTP_HOST = %q; expect %q", host, "localhost")
+	}
+
+	if err := env.Check("CLIENT_SECRET", "HTTP_HOST", "BROKEN"); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("DecryptedEnvironment.Check() = %v; expect %v", err, ErrInvalidCiphertext)
+	}
+}
+
+func TestDecrypted(t *testing.T) {
+	t.Parallel()
+
+	keyring := NewKeyring()
+	if err := keyring.Add("2025", generateTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := keyring.Encrypt("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := MapEnvironment{
+		"CLIENT_SECRET": encrypted,
+		"HTTP_HOST":     "localhost",
+		"BROKEN":        EncryptedPrefix + "2025:AAAA",
+	}
+
+	type Test struct {
+		key      string
+		expect   string
+		expectOk bool
+	}
+
+	tests := []Test{
+		{key: "CLIENT_SECRET", expect: "hunter2", expectOk: true},
+		{key: "HTTP_HOST", expect: "localhost", expectOk: true},
+		{key: "BROKEN", expect: "", expectOk: false},
+		{key: "UNSET", expect: "", expectOk: false},
+	}
+
+	for _, test := range tests {
+		result := Resolve(Decrypted(keyring, ConvString(EnvironmentVariable(test.key).In(env), false)))
+		if result.Value != test.expect || result.Set != test.expectOk {
+			t.Errorf("Decrypted(%s) = %q, %v; expect %q, %v", test.key, result.Value, result.Set, test.expect, test.expectOk)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Environment loaded from a .env file.
+// Create with LoadEnvFile().
+type FileEnvironment struct {
+	path   string
+	values map[string]string // Current values by key.
+	mutex  sync.RWMutex      // Sync access to values.
+}
+
+// Load a .env file containing KEY=VALUE lines.
+// Blank lines, comments starting with "#" and "export " prefixes are ignored.
+// Values may be wrapped in single quotes, or double quotes with Go escape sequences,
+// and may be followed by a comment starting with " #".
+func LoadEnvFile(path string) (*FileEnvironment, error) {
+	f := &FileEnvironment{
+		path: path,
+	}
+	if _, err := f.Refresh(); err != nil {
+		return nil, fmt.Errorf("LoadEnvFile: %w", err)
+	}
+	return f, nil
+}
+
+// Read the values from the file again.
+// Returns true if the values have changed. On error the previous values are kept.
+func (f *FileEnvironment) Refresh() (bool, error) {
+	values, err := readEnvFile(f.path)
+	if err != nil {
+		return false, fmt.Errorf("FileEnvironment.Refresh(): %w", err)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	changed := !maps.Equal(f.values, values)
+	f.values = values
+	return changed, nil
+}
+
+// Implement the Reloader interface, refreshing the values and logging any error.
+// Pass the environment before the settings that read it, e.g.
+// ReloadOnFileChange(ctx, path, interval, env, setting).
+func (f *FileEnvironment) Reload() {
+	if _, err := f.Refresh(); err != nil {
+		slog.Warn(
+			"failed to refresh configuration file",
+			slog.String("path", f.path),
+			slog.Any("err", err),
+		)
+	}
+}
+
+// Read the values of a .env file.
+func readEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, err := parseEnvLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, number, err)
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// Parse a single KEY=VALUE line.
+func parseEnvLine(line string) (string, string, error) {
+	line = strings.TrimPrefix(line, "export ")
+	key, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", "", fmt.Errorf("expected KEY=VALUE, got %q", line)
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", "", fmt.Errorf("missing key in %q", line)
+	}
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(value, `"`):
+		quoted, err := strconv.QuotedPrefix(value)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		if !isComment(value[len(quoted):]) {
+			return "", "", fmt.Errorf("unexpected text after quoted value for %s", key)
+		}
+		value, _ = strconv.Unquote(quoted) // Ignore returned error, QuotedPrefix has validated the value.
+	case strings.HasPrefix(value, "'"):
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted value for %s", key)
+		}
+		if !isComment(value[end+2:]) {
+			return "", "", fmt.Errorf("unexpected text after quoted value for %s", key)
+		}
+		value = value[1 : end+1]
+	default:
+		value = stripComment(value)
+	}
+	return key, value, nil
+}
+
+// Is text empty or only a comment, ignoring leading whitespace?
+func isComment(text string) bool {
+	text = strings.TrimSpace(text)
+	return text == "" || strings.HasPrefix(text, "#")
+}
+
+// Remove a trailing comment from an unquoted value.
+// Comments start with a "#" at the start of the value or after whitespace,
+// so values such as "a#b" are kept whole.
+func stripComment(value string) string {
+	if strings.HasPrefix(value, "#") {
+		return ""
+	}
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return value
+}
+
+// Implement the Environment interface.
+func (f *FileEnvironment) LookupEnv(key string) (string, bool) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	value, ok := f.values[key]
+	return value, ok
+}
+
+// Return the kind of source a key is read from.
+func (f *FileEnvironment) SourceOf(key string) string {
+	return "file:" + f.path
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadEnvFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "# Comment\n" +
+		"\n" +
+		"HTTP_PORT=5000\n" +
+		"export HTTP_HOST = localhost\n" +
+		"GREETING=\"hello\\nworld\"\n" +
+		"LITERAL='${NOT_EXPANDED}'\n" +
+		"EMPTY=\n" +
+		"COMMENTED=5000 # Trailing comment\n" +
+		"FRAGMENT=http://example.com/#top\n" +
+		"QUOTED_HASH=\"a # b\" # Trailing comment\n" +
+		"SINGLE_QUOTED='a # b' # Trailing comment\n" +
+		"ONLY_COMMENT= # Trailing comment\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Test struct {
+		key      string
+		expect   string
+		expectOk bool
+	}
+
+	tests := []Test{
+		{key: "HTTP_PORT", expect: "5000", expectOk: true},
+		{key: "HTTP_HOST", expect: "localhost", expectOk: true},
+		{key: "GREETING", expect: "hello\nworld", expectOk: true},
+		{key: "LITERAL", expect: "${NOT_EXPANDED}", expectOk: true},
+		{key: "EMPTY", expect: "", expectOk: true},
+		{key: "COMMENTED", expect: "5000", expectOk: true},
+		{key: "FRAGMENT", expect: "http://example.com/#top", expectOk: true},
+		{key: "QUOTED_HASH", expect: "a # b", expectOk: true},
+		{key: "SINGLE_QUOTED", expect: "a # b", expectOk: true},
+		{key: "ONLY_COMMENT", expect: "", expectOk: true},
+		{key: "UNSET", expect: "", expectOk: false},
+	}
+
+	for _, test := range tests {
+		t.Run("LookupEnv(\""+test.key+"\")", func(t *testing.T) {
+			result, ok := env.LookupEnv(test.key)
+			if result != test.expect || ok != test.expectOk {
+				t.Errorf("FileEnvironment.LookupEnv() = %q, %v; expect %q, %v", result, ok, test.expect, test.expectOk)
+			}
+		})
+	}
+}
+
+func TestLoadEnvFile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		"NOT A SETTING\n",
+		"QUOTED=\"unterminated\n",
+		"QUOTED=\"value\" trailing\n",
+		"SINGLE_QUOTED='unterminated\n",
+		"SINGLE_QUOTED='value' trailing\n",
+	}
+
+	for _, content := range tests {
+		path := filepath.Join(t.TempDir(), ".env")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := LoadEnvFile(path); err == nil {
+			t.Errorf("LoadEnvFile(%q) error = nil; expect an error", content)
+		}
+	}
+}
+
+func TestFileEnvironment_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("HTTP_PORT=5000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setting := NewLiveSetting(ConvInt(NewVariable(env, "HTTP_PORT")))
+	changed := make(chan int, 1)
+	setting.Subscribe(func(value int) {
+		changed <- value
+	})
+	ReloadOnFileChange(t.Context(), path, 10*time.Millisecond, env, setting)
+
+	if err := os.WriteFile(path, []byte("HTTP_PORT=60000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case value := <-changed:
+		if value != 60000 {
+			t.Errorf("LiveSetting.Get() = %d; expect %d", value, 60000)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the setting to reload")
+	}
+
+	// Invalid edits keep the previous values.
+	if err := os.WriteFile(path, []byte("HTTP_PORT\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.Refresh(); err == nil {
+		t.Errorf("FileEnvironment.Refresh() error = nil; expect an invalid file to fail")
+	}
+	if value, _ := env.LookupEnv("HTTP_PORT"); value != "60000" {
+		t.Errorf("FileEnvironment.LookupEnv() = %q; expect the previous value %q", value, "60000")
+	}
+}
@@ -0,0 +1,195 @@
+package config
+
+import "os"
+
+// Defines a source of environment variables.
+type Environment interface {
+	LookupEnv(key string) (string, bool)
+}
+
+// Implemented by environments that can describe where a key is read from.
+type environmentSourcer interface {
+	SourceOf(key string) string
+}
+
+// Implemented by environments that store keys under a different name.
+type environmentQualifier interface {
+	QualifyKey(key string) string
+}
+
+// Implemented by environments that can tell whether a key holds a secret, e.g. DecryptedEnvironment.
+type environmentSecreter interface {
+	IsSecret(key string) bool
+}
+
+// Does a key hold a secret in an environment?
+func isSecretKey(env Environment, key string) bool {
+	if s, ok := env.(environmentSecreter); ok {
+		return s.IsSecret(key)
+	}
+	return false
+}
+
+// Return the kind of source a key is read from in an environment.
+func sourceOf(env Environment, key string) string {
+	if s, ok := env.(environmentSourcer); ok {
+		return s.SourceOf(key)
+	}
+	return "env"
+}
+
+// Return the name a key is stored under in an environment.
+func qualifyKey(env Environment, key string) string {
+	if q, ok := env.(environmentQualifier); ok {
+		return q.QualifyKey(key)
+	}
+	return key
+}
+
+// Operating system environment.
+type osEnvironment struct{}
+
+// Operating system environment.
+var OS Environment = osEnvironment{}
+
+// Implement the Environment interface.
+func (osEnvironment) LookupEnv(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// Environment backed by a map, e.g. for tests.
+type MapEnvironment map[string]string
+
+// Implement the Environment interface.
+func (m MapEnvironment) LookupEnv(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// Return the kind of source a key is read from.
+func (m MapEnvironment) SourceOf(key string) string {
+	return "map"
+}
+
+// Environment that searches each layer in order, the first layer containing a key wins.
+type LayeredEnvironment []Environment
+
+// Implement the Environment interface.
+func (l LayeredEnvironment) LookupEnv(key string) (string, bool) {
+	for _, env := range l {
+		if value, ok := env.LookupEnv(key); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Return the kind of source a key is read from.
+func (l LayeredEnvironment) SourceOf(key string) string {
+	return sourceOf(l.layer(key), key)
+}
+
+// Does the layer containing key hold it as a secret?
+func (l LayeredEnvironment) IsSecret(key string) bool {
+	return isSecretKey(l.layer(key), key)
+}
+
+// Return the name a key is stored under.
+func (l LayeredEnvironment) QualifyKey(key string) string {
+	return qualifyKey(l.layer(key), key)
+}
+
+// Return the first layer containing key.
+// If no layer contains the key the first layer is returned.
+func (l LayeredEnvironment) layer(key string) Environment {
+	for _, env := range l {
+		if _, ok := env.LookupEnv(key); ok {
+			return env
+		}
+	}
+	if len(l) == 0 {
+		return OS
+	}
+	return l[0]
+}
+
+// Environment that namespaces every key with a prefix, e.g. "APP_".
+// Create with WithPrefix().
+type PrefixedEnvironment struct {
+	env    Environment
+	prefix string
+}
+
+// Create a PrefixedEnvironment.
+func WithPrefix(env Environment, prefix string) PrefixedEnvironment {
+	return PrefixedEnvironment{
+		env:    env,
+		prefix: prefix,
+	}
+}
+
+// Implement the Environment interface.
+func (p PrefixedEnvironment) LookupEnv(key string) (string, bool) {
+	return p.env.LookupEnv(p.prefix + key)
+}
+
+// Return the kind of source a key is read from.
+func (p PrefixedEnvironment) SourceOf(key string) string {
+	return sourceOf(p.env, p.prefix+key)
+}
+
+// Does the environment hold key as a secret?
+func (p PrefixedEnvironment) IsSecret(key string) bool {
+	return isSecretKey(p.env, p.prefix+key)
+}
+
+// Return the name a key is stored under.
+func (p PrefixedEnvironment) QualifyKey(key string) string {
+	return qualifyKey(p.env, p.prefix+key)
+}
+
+// Variable read from a specific environment.
+// Create with NewVariable() or EnvironmentVariable.In().
+type Variable struct {
+	env Environment
+	key string
+}
+
+// Create a new Variable.
+func NewVariable(env Environment, key string) Variable {
+	return Variable{
+		env: env,
+		key: key,
+	}
+}
+
+// Return the variable key, including any environment prefix.
+func (v Variable) Key() string {
+	return qualifyKey(v.env, v.key)
+}
+
+// Return the variable value. If the variable is not set an empty string is returned.
+func (v Variable) Get() string {
+	value, _ := v.env.LookupEnv(v.key)
+	return value
+}
+
+// Return the variable value. If the variable is not set false is returned as the second value.
+func (v Variable) Lookup() (string, bool) {
+	return v.env.LookupEnv(v.key)
+}
+
+// Return the kind of source the value is read from.
+func (v Variable) Source() string {
+	return sourceOf(v.env, v.key)
+}
+
+// Is the value read from a secret source, e.g. an encrypted value?
+func (v Variable) IsSecret() bool {
+	return isSecretKey(v.env, v.key)
+}
+
+// Implement the fmt.Stringer interface.
+func (v Variable) String() string {
+	return v.Get()
+}
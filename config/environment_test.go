@@ -0,0 +1,68 @@
+package config
+
+import "testing"
+
+func TestVariable_Lookup(t *testing.T) {
+	t.Parallel()
+
+	base := MapEnvironment{
+		"HTTP_PORT":     "80",
+		"APP_HTTP_PORT": "5000",
+		"APP_HTTP_HOST": "localhost",
+	}
+	override := MapEnvironment{
+		"APP_HTTP_HOST": "example.com",
+	}
+
+	type Test struct {
+		name      string
+		env       Environment
+		key       string
+		expect    string
+		expectOk  bool
+		expectKey string
+	}
+
+	tests := []Test{
+		{name: "Lookup() should read the map", env: base, key: "HTTP_PORT", expect: "80", expectOk: true, expectKey: "HTTP_PORT"},
+		{name: "Lookup() should apply the prefix", env: WithPrefix(base, "APP_"), key: "HTTP_PORT", expect: "5000", expectOk: true, expectKey: "APP_HTTP_PORT"},
+		{name: "Lookup() should prefer the first layer", env: WithPrefix(LayeredEnvironment{override, base}, "APP_"), key: "HTTP_HOST", expect: "example.com", expectOk: true, expectKey: "APP_HTTP_HOST"},
+		{name: "Lookup() should fall through layers", env: WithPrefix(LayeredEnvironment{override, base}, "APP_"), key: "HTTP_PORT", expect: "5000", expectOk: true, expectKey: "APP_HTTP_PORT"},
+		{name: "Lookup() should fail when unset", env: LayeredEnvironment{override, base}, key: "UNSET", expect: "", expectOk: false, expectKey: "UNSET"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			variable := NewVariable(test.env, test.key)
+			result, ok := variable.Lookup()
+			if result != test.expect || ok != test.expectOk {
+				t.Errorf("Variable.Lookup() = %q, %v; expect %q, %v", result, ok, test.expect, test.expectOk)
+			}
+			if key := variable.Key(); key != test.expectKey {
+				t.Errorf("Variable.Key() = %q; expect %q", key, test.expectKey)
+			}
+		})
+	}
+}
+
+func TestVariable_Origin(t *testing.T) {
+	t.Parallel()
+
+	env := WithPrefix(LayeredEnvironment{MapEnvironment{"APP_HTTP_PORT": "5000"}, OS}, "APP_")
+	setting := Resolve(ConvInt(EnvironmentVariable("HTTP_PORT").In(env)))
+	if result := setting.Origin.String(); result != "map:APP_HTTP_PORT" {
+		t.Errorf("Setting.Origin = %q; expect %q", result, "map:APP_HTTP_PORT")
+	}
+}
+
+func TestSecretVariable_In(t *testing.T) {
+	t.Parallel()
+
+	env := WithPrefix(MapEnvironment{"APP_CLIENT_SECRET": "hunter2"}, "APP_")
+	result, ok := NewSecretVariable("CLIENT_SECRET").In(env).Lookup()
+	if result != "hunter2" || !ok {
+		t.Errorf("SecretVariable.Lookup() = %q, %v; expect %q, true", result, ok, "hunter2")
+	}
+}
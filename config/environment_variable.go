@@ -20,6 +20,16 @@ func (e EnvironmentVariable) Lookup() (string, bool) {
 	return os.LookupEnv(string(e))
 }
 
+// Read the variable from a specific environment instead of the OS environment.
+func (e EnvironmentVariable) In(env Environment) Variable {
+	return NewVariable(env, string(e))
+}
+
+// Return the kind of source the value is read from.
+func (e EnvironmentVariable) Source() string {
+	return "env"
+}
+
 // Implement the fmt.Stringer interface.
 func (e EnvironmentVariable) String() string {
 	return os.Getenv(string(e))
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"bytes"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/jrrdcnnlly/core/logging"
+)
+
+// Page listing the effective configuration.
+var configTemplate = template.Must(template.New("config").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Configuration</title>
+</head>
+<body>
+<h1>Configuration</h1>
+{{if .Profile}}<p>Profile: <code>{{.Profile}}</code></p>
+{{end}}<table>
+<thead>
+<tr><th>Key</th><th>Value</th><th>Source</th><th>Default</th><th>Description</th></tr>
+</thead>
+<tbody>
+{{range .Settings}}<tr><td><code>{{.Key}}</code></td><td>{{if .Set}}<code>{{.Value}}</code>{{end}}</td><td>{{.Source}}</td><td>{{if .Default}}<code>{{.Default}}</code>{{end}}</td><td>{{.Description}}</td></tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+// Create a handler serving the effective configuration of a registry.
+// Secrets, including values read from secret sources, are redacted.
+// Responds with JSON if requested by the Accept header or a "format=json" query parameter, and HTML otherwise.
+// The active profile is sent in the Config-Profile header.
+// The configuration may reveal how a service is deployed, so serve it behind authentication.
+func Handler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Get logger from request context.
+		logger := logging.FromContextOrDefault(req.Context())
+
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Render the full response before writing so errors can still be reported.
+		var body bytes.Buffer
+		var contentType string
+		var err error
+		if wantsJSON(req) {
+			contentType = "application/json"
+			err = r.WriteJSON(&body)
+		} else {
+			contentType = "text/html; charset=utf-8"
+			err = configTemplate.Execute(&body, Report{
+				Profile:  r.Profile(),
+				Settings: r.Entries(),
+			})
+		}
+		if err != nil {
+			logger.Error(
+				"failed to render configuration",
+				slog.Any("err", err),
+			)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if profile := r.Profile(); profile != "" {
+			w.Header().Set("Config-Profile", profile)
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Vary", "Accept")
+		w.Write(body.Bytes())
+	})
+}
+
+// Does the request prefer a JSON response?
+func wantsJSON(r *http.Request) bool {
+	switch r.URL.Query().Get("format") {
+	case "json":
+		return true
+	case "html":
+		return false
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	env := MapEnvironment{
+		"HTTP_PORT":     "5000",
+		"CLIENT_SECRET": "hunter2",
+		"GREETING":      "<script>",
+	}
+	registry := NewRegistry()
+	registry.SetProfile("prod")
+	Define(registry, "HTTP_PORT", "Port to listen on.", ConvInt(EnvironmentVariable("HTTP_PORT").In(env)), Fallback(80))
+	Define(registry, "CLIENT_SECRET", "MSAL client secret.", AsSecret(ConvString(NewSecretVariable("CLIENT_SECRET").In(env), false)))
+	Register(registry, "GREETING", ConvString(EnvironmentVariable("GREETING").In(env), false))
+	handler := Handler(registry)
+
+	type Test struct {
+		name         string
+		target       string
+		accept       string
+		expectType   string
+		expectToFind []string
+	}
+
+	tests := []Test{
+		{name: "Handler() should serve HTML", target: "/", accept: "text/html", expectType: "text/html; charset=utf-8", expectToFind: []string{"<code>prod</code>", "<code>HTTP_PORT</code>", "map:HTTP_PORT", "Port to listen on.", "&lt;script&gt;"}},
+		{name: "Handler() should serve JSON by Accept", target: "/", accept: "application/json", expectType: "application/json", expectToFind: []string{`"profile": "prod"`, `"source": "map:HTTP_PORT"`, `"default": "80"`}},
+		{name: "Handler() should serve JSON by query", target: "/?format=json", accept: "text/html", expectType: "application/json", expectToFind: []string{`"key": "CLIENT_SECRET"`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, test.target, nil)
+			req.Header.Set("Accept", test.accept)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("status = %d; expect %d", res.Code, http.StatusOK)
+			}
+			if contentType := res.Header().Get("Content-Type"); contentType != test.expectType {
+				t.Errorf("Content-Type = %q; expect %q", contentType, test.expectType)
+			}
+			if profile := res.Header().Get("Config-Profile"); profile != "prod" {
+				t.Errorf("Config-Profile = %q; expect %q", profile, "prod")
+			}
+			body := res.Body.String()
+			if strings.Contains(body, "hunter2") {
+				t.Errorf("body = %q; expect secrets to be redacted", body)
+			}
+			for _, expect := range test.expectToFind {
+				if !strings.Contains(body, expect) {
+					t.Errorf("body = %q; expect to contain %q", body, expect)
+				}
+			}
+			if strings.HasPrefix(test.expectType, "application/json") && !json.Valid(res.Body.Bytes()) {
+				t.Errorf("body = %q; expect valid JSON", body)
+			}
+		})
+	}
+}
+
+func TestHandler_MethodNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	res := httptest.NewRecorder()
+	Handler(NewRegistry()).ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/", nil))
+	if res.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d; expect %d", res.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandler_SecretSources(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "CLIENT_SECRET"), []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The secret is not wrapped with AsSecret(); its source alone should redact it.
+	registry := NewRegistry()
+	Register(registry, "CLIENT_SECRET", ConvString(NewSecretVariable("CLIENT_SECRET", dir).In(MapEnvironment{}), false))
+	handler := Handler(registry)
+
+	for _, target := range []string{"/", "/?format=json"} {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, target, nil))
+		if res.Code != http.StatusOK {
+			t.Fatalf("status = %d; expect %d", res.Code, http.StatusOK)
+		}
+		if body := res.Body.String(); strings.Contains(body, "hunter2") || !strings.Contains(body, redacted) {
+			t.Errorf("GET %s body = %q; expect secret to be redacted", target, body)
+		}
+	}
+}
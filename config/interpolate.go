@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+var (
+	// Returned when a value references a key that is not set and has no default.
+	ErrUndefinedReference = errors.New("undefined reference")
+	// Returned when a value references itself, directly or indirectly.
+	ErrReferenceCycle = errors.New("reference cycle")
+	// Returned when a reference is not terminated or has no key.
+	ErrInvalidReference = errors.New("invalid reference")
+)
+
+// Expand ${KEY} and ${KEY:-default} references in value from env.
+// Referenced values are expanded recursively and "$$" is replaced by "$".
+func Expand(env Environment, value string) (string, error) {
+	return expand(env, value, nil)
+}
+
+// Expand value, stack holds the keys currently being expanded.
+func expand(env Environment, value string, stack []string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		switch value[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := closingBrace(value, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("%w: %q is not terminated", ErrInvalidReference, value[i:])
+			}
+			expanded, err := expandReference(env, value[i+2:end], stack)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+			i = end
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// Expand the body of a single reference, e.g. "KEY:-default".
+func expandReference(env Environment, reference string, stack []string) (string, error) {
+	key, fallback, hasFallback := strings.Cut(reference, ":-")
+	if key == "" {
+		return "", fmt.Errorf("%w: \"${%s}\" has no key", ErrInvalidReference, reference)
+	}
+	for i, k := range stack {
+		if k == key {
+			cycle := append(append([]string{}, stack[i:]...), key)
+			return "", fmt.Errorf("%w: %s", ErrReferenceCycle, strings.Join(cycle, " -> "))
+		}
+	}
+
+	value, ok := env.LookupEnv(key)
+	if !ok || (value == "" && hasFallback) {
+		if !hasFallback {
+			if len(stack) == 0 {
+				return "", fmt.Errorf("%w: ${%s}", ErrUndefinedReference, key)
+			}
+			return "", fmt.Errorf("%w: ${%s} referenced by %s", ErrUndefinedReference, key, stack[len(stack)-1])
+		}
+		return expand(env, fallback, stack)
+	}
+	return expand(env, value, append(stack, key))
+}
+
+// Return the index of the brace closing a reference starting at start.
+// Nested references are skipped. Returns -1 if the reference is not terminated.
+func closingBrace(value string, start int) int {
+	depth := 0
+	for i := start; i < len(value); i++ {
+		switch {
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '{':
+			depth++
+			i++
+		case value[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// Environment that expands references in the values of another environment.
+// Create with Interpolate().
+type InterpolatedEnvironment struct {
+	env Environment
+}
+
+// Create an InterpolatedEnvironment. References are resolved from env.
+func Interpolate(env Environment) InterpolatedEnvironment {
+	return InterpolatedEnvironment{env: env}
+}
+
+// Implement the Environment interface.
+// Values that fail to expand are logged and reported as not set.
+func (i InterpolatedEnvironment) LookupEnv(key string) (string, bool) {
+	value, ok, err := i.Expand(key)
+	if err != nil {
+		slog.Warn(
+			"failed to expand configuration value",
+			slog.String("key", qualifyKey(i.env, key)),
+			slog.Any("err", err),
+		)
+		return "", false
+	}
+	return value, ok
+}
+
+// Return the expanded value of key.
+// If the key is not set false is returned as the second value.
+func (i InterpolatedEnvironment) Expand(key string) (string, bool, error) {
+	value, ok := i.env.LookupEnv(key)
+	if !ok {
+		return "", false, nil
+	}
+	expanded, err := expand(i.env, value, []string{key})
+	if err != nil {
+		return "", true, fmt.Errorf("%s: %w", qualifyKey(i.env, key), err)
+	}
+	return expanded, true, nil
+}
+
+// Check that the values of keys expand without errors.
+func (i InterpolatedEnvironment) Check(keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		if _, _, err := i.Expand(key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Return the kind of source a key is read from.
+func (i InterpolatedEnvironment) SourceOf(key string) string {
+	return sourceOf(i.env, key)
+}
+
+// Does the environment hold key as a secret?
+func (i InterpolatedEnvironment) IsSecret(key string) bool {
+	return isSecretKey(i.env, key)
+}
+
+// Return the name a key is stored under.
+func (i InterpolatedEnvironment) QualifyKey(key string) string {
+	return qualifyKey(i.env, key)
+}
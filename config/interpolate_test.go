@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	env := MapEnvironment{
+		"BASE_URL":     "https://example.com",
+		"REDIRECT_URL": "${BASE_URL}/callback",
+		"NESTED":       "${REDIRECT_URL}?next=/",
+		"EMPTY":        "",
+		"CYCLE_A":      "${CYCLE_B}",
+		"CYCLE_B":      "${CYCLE_A}",
+		"SELF":         "${SELF}",
+		"BROKEN":       "${UNDEFINED}",
+	}
+
+	type Test struct {
+		name      string
+		value     string
+		expect    string
+		expectErr error
+	}
+
+	tests := []Test{
+		{name: "Expand() should leave plain values", value: "plain", expect: "plain"},
+		{name: "Expand() should expand references", value: "${BASE_URL}/callback", expect: "https://example.com/callback"},
+		{name: "Expand() should expand recursively", value: "${NESTED}", expect: "https://example.com/callback?next=/"},
+		{name: "Expand() should use defaults", value: "${UNDEFINED:-http://localhost}", expect: "http://localhost"},
+		{name: "Expand() should use defaults for empty values", value: "${EMPTY:-fallback}", expect: "fallback"},
+		{name: "Expand() should expand defaults", value: "${UNDEFINED:-${BASE_URL}}/", expect: "https://example.com/"},
+		{name: "Expand() should escape $$", value: "$${BASE_URL} costs $5", expect: "${BASE_URL} costs $5"},
+		{name: "Expand() should fail on undefined references", value: "${UNDEFINED}", expectErr: ErrUndefinedReference},
+		{name: "Expand() should fail on nested undefined references", value: "${BROKEN}", expectErr: ErrUndefinedReference},
+		{name: "Expand() should fail on cycles", value: "${CYCLE_A}", expectErr: ErrReferenceCycle},
+		{name: "Expand() should fail on self references", value: "${SELF}", expectErr: ErrReferenceCycle},
+		{name: "Expand() should fail on unterminated references", value: "${BASE_URL", expectErr: ErrInvalidReference},
+		{name: "Expand() should fail on empty references", value: "${}", expectErr: ErrInvalidReference},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := Expand(env, test.value)
+			if !errors.Is(err, test.expectErr) {
+				t.Fatalf("Expand() error = %v; expect %v", err, test.expectErr)
+			}
+			if result != test.expect {
+				t.Errorf("Expand() = %q; expect %q", result, test.expect)
+			}
+		})
+	}
+}
+
+func TestInterpolatedEnvironment(t *testing.T) {
+	t.Parallel()
+
+	env := Interpolate(MapEnvironment{
+		"BASE_URL":     "https://example.com",
+		"REDIRECT_URL": "${BASE_URL}/callback",
+		"CYCLE":        "${CYCLE}",
+	})
+
+	redirectURL := Setting[string]{}.Resolve(
+		ConvString(EnvironmentVariable("REDIRECT_URL").In(env), false),
+	)
+	if redirectURL != "https://example.com/callback" {
+		t.Errorf("REDIRECT_URL = %q; expect %q", redirectURL, "https://example.com/callback")
+	}
+
+	cycle := Setting[string]{}.Resolve(
+		ConvString(EnvironmentVariable("CYCLE").In(env), false),
+		Fallback("fallback"),
+	)
+	if cycle != "fallback" {
+		t.Errorf("CYCLE = %q; expect %q", cycle, "fallback")
+	}
+
+	err := env.Check("BASE_URL", "REDIRECT_URL", "CYCLE")
+	if !errors.Is(err, ErrReferenceCycle) {
+		t.Errorf("InterpolatedEnvironment.Check() = %v; expect %v", err, ErrReferenceCycle)
+	}
+}
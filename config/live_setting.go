@@ -0,0 +1,93 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jrrdcnnlly/core/id"
+)
+
+// Application setting that is re-resolved while the application is running.
+// Create with NewLiveSetting().
+type LiveSetting[T any] struct {
+	resolvers   []Resolver[T]
+	setting     atomic.Pointer[Setting[T]] // Current setting.
+	ids         *id.SequentialGenerator    // Subscription IDs.
+	subscribers map[uint64]func(T)         // Change subscribers by ID.
+	mutex       sync.Mutex                 // Sync access to subscribers.
+	reload      sync.Mutex                 // Serialize reloads.
+}
+
+// Create a new LiveSetting and resolve its initial value.
+func NewLiveSetting[T any](resolvers ...Resolver[T]) *LiveSetting[T] {
+	s := &LiveSetting[T]{
+		resolvers:   resolvers,
+		ids:         id.NewSequentialGenerator(),
+		subscribers: map[uint64]func(T){},
+	}
+	setting := Resolve(s.resolvers...)
+	s.setting.Store(&setting)
+	return s
+}
+
+// Return the current setting value.
+func (s *LiveSetting[T]) Get() T {
+	return s.setting.Load().Value
+}
+
+// Return the current setting, including its origin.
+func (s *LiveSetting[T]) Setting() Setting[T] {
+	return *s.setting.Load()
+}
+
+// Re-resolve the setting value.
+// If the value has changed every subscriber is notified.
+func (s *LiveSetting[T]) Reload() {
+	s.reload.Lock()
+	defer s.reload.Unlock()
+
+	setting := Resolve(s.resolvers...)
+	previous := s.setting.Swap(&setting)
+	if reflect.DeepEqual(previous.Value, setting.Value) {
+		return
+	}
+
+	// Copy subscribers so they may (un)subscribe when notified.
+	s.mutex.Lock()
+	subscribers := make([]func(T), 0, len(s.subscribers))
+	for _, fn := range s.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	s.mutex.Unlock()
+
+	for _, fn := range subscribers {
+		fn(setting.Value)
+	}
+}
+
+// Call fn with the new value every time the setting changes.
+// Returns a function that cancels the subscription.
+func (s *LiveSetting[T]) Subscribe(fn func(value T)) func() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := s.ids.Next()
+	s.subscribers[key] = fn
+	return func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		delete(s.subscribers, key)
+	}
+}
+
+// Create a slog.LevelVar that follows a live log level setting.
+// Pass the result to a slog.Handler to change the log level without a restart.
+func LiveLevel(s *LiveSetting[slog.Level]) *slog.LevelVar {
+	level := &slog.LevelVar{}
+	level.Set(s.Get())
+	s.Subscribe(level.Set)
+	return level
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestLiveSetting_Reload(t *testing.T) {
+	t.Setenv("TESTING", "5000")
+	env := EnvironmentVariable("TESTING")
+	setting := NewLiveSetting(ConvInt(env), Fallback(80))
+
+	if result := setting.Get(); result != 5000 {
+		t.Errorf("LiveSetting.Get() = %d; expect %d", result, 5000)
+	}
+
+	var notified []int
+	unsubscribe := setting.Subscribe(func(value int) {
+		notified = append(notified, value)
+	})
+
+	t.Setenv("TESTING", "6000")
+	setting.Reload()
+	if result := setting.Get(); result != 6000 {
+		t.Errorf("LiveSetting.Get() = %d; expect %d", result, 6000)
+	}
+
+	// Unchanged values should not notify subscribers.
+	setting.Reload()
+
+	unsubscribe()
+	t.Setenv("TESTING", "7000")
+	setting.Reload()
+
+	if len(notified) != 1 || notified[0] != 6000 {
+		t.Errorf("LiveSetting.Subscribe() notified %v; expect [6000]", notified)
+	}
+}
+
+func TestLiveLevel(t *testing.T) {
+	t.Setenv("TESTING", "info")
+	env := EnvironmentVariable("TESTING")
+	setting := NewLiveSetting(ConvLevel(env))
+	level := LiveLevel(setting)
+
+	if result := level.Level(); result != slog.LevelInfo {
+		t.Errorf("LiveLevel().Level() = %v; expect %v", result, slog.LevelInfo)
+	}
+
+	t.Setenv("TESTING", "debug")
+	setting.Reload()
+	if result := level.Level(); result != slog.LevelDebug {
+		t.Errorf("LiveLevel().Level() = %v; expect %v", result, slog.LevelDebug)
+	}
+}
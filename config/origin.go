@@ -0,0 +1,55 @@
+package config
+
+import "fmt"
+
+// Describes where a setting value was resolved from.
+type Origin struct {
+	Resolver string // Resolver that set the value, e.g. "ConvInt".
+	Source   string // Kind of source, e.g. "env" or "default".
+	Key      string // Key within the source, e.g. "HTTP_PORT".
+	Secret   bool   // Was the value read from a secret source, e.g. a secret file or encrypted value?
+}
+
+// Implemented by values that have a key, e.g. EnvironmentVariable.
+type keyer interface {
+	Key() string
+}
+
+// Implemented by values that can describe their kind of source, e.g. EnvironmentVariable.
+type sourcer interface {
+	Source() string
+}
+
+// Implemented by values that can tell whether they are read from a secret source, e.g. SecretVariable.
+type secretSourcer interface {
+	IsSecret() bool
+}
+
+// Create the origin of a value resolved from a source.
+func originOf(resolver string, value fmt.Stringer) Origin {
+	origin := Origin{Resolver: resolver}
+	if k, ok := value.(keyer); ok {
+		origin.Key = k.Key()
+	}
+	if s, ok := value.(sourcer); ok {
+		origin.Source = s.Source()
+	}
+	if s, ok := value.(secretSourcer); ok {
+		origin.Secret = s.IsSecret()
+	}
+	return origin
+}
+
+// Implement the fmt.Stringer interface.
+func (o Origin) String() string {
+	switch {
+	case o.Source == "" && o.Key == "":
+		return o.Resolver
+	case o.Key == "":
+		return o.Source
+	case o.Source == "":
+		return o.Key
+	default:
+		return o.Source + ":" + o.Key
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import "testing"
+
+func TestResolve_Origin(t *testing.T) {
+	type Test struct {
+		name   string
+		env    map[string]string
+		expect string
+	}
+
+	tests := []Test{
+		{name: "Origin should be the environment", env: map[string]string{"TESTING": "5000"}, expect: "env:TESTING"},
+		{name: "Origin should be the default", env: map[string]string{}, expect: "default"},
+		{name: "Origin should be the default for invalid values", env: map[string]string{"TESTING": "invalid"}, expect: "default"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+			env := EnvironmentVariable("TESTING")
+			setting := Resolve(ConvInt(env), Fallback(80))
+			if result := setting.Origin.String(); result != test.expect {
+				t.Errorf("Setting.Origin = %q; expect %q", result, test.expect)
+			}
+			if setting.Default == nil || *setting.Default != 80 {
+				t.Errorf("Setting.Default = %v; expect 80", setting.Default)
+			}
+		})
+	}
+}
+
+func TestResolve_SecretOrigin(t *testing.T) {
+	t.Setenv("TESTING_FILE", "/dev/null")
+	setting := Resolve(AsSecret(ConvString(NewSecretVariable("TESTING"), true)))
+	if result := setting.Origin.String(); result != "file:TESTING" {
+		t.Errorf("Setting.Origin = %q; expect %q", result, "file:TESTING")
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Environment variable selecting the active profile.
+const ProfileVariable string = "APP_PROFILE"
+
+// Command line flag selecting the active profile, e.g. --profile=prod.
+const profileFlag string = "profile"
+
+// Returned when the selected profile has not been declared.
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// Profile specific environments layered over the base environment, by profile name.
+type Profiles map[string]Environment
+
+// Load a profile for each name from the file ".env.<name>" in dir.
+// Missing files result in an empty profile.
+func LoadProfiles(dir string, names ...string) (Profiles, error) {
+	profiles := Profiles{}
+	for _, name := range names {
+		env, err := LoadEnvFile(filepath.Join(dir, ".env."+name))
+		if errors.Is(err, os.ErrNotExist) {
+			profiles[name] = MapEnvironment{}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("LoadProfiles: %w", err)
+		}
+		profiles[name] = env
+	}
+	return profiles, nil
+}
+
+// Return the profile names in sorted order.
+func (p Profiles) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select the active profile from a --profile flag in args, or APP_PROFILE in env.
+// The flag takes precedence. An empty string is returned if no profile is selected.
+// Returns ErrUnknownProfile if the selected profile has not been declared.
+func (p Profiles) Select(env Environment, args []string) (string, error) {
+	name, ok := profileFromArgs(args)
+	if !ok {
+		name, _ = env.LookupEnv(ProfileVariable)
+	}
+	if name == "" {
+		return "", nil
+	}
+	if _, ok := p[name]; !ok {
+		return "", fmt.Errorf("%w %q, expected one of %s", ErrUnknownProfile, name, strings.Join(p.Names(), ", "))
+	}
+	return name, nil
+}
+
+// Create an environment layering the named profile over base.
+// If name is empty base is returned.
+// Returns ErrUnknownProfile if the profile has not been declared.
+func (p Profiles) Environment(name string, base Environment) (Environment, error) {
+	if name == "" {
+		return base, nil
+	}
+	env, ok := p[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, expected one of %s", ErrUnknownProfile, name, strings.Join(p.Names(), ", "))
+	}
+	return LayeredEnvironment{profileEnvironment{name: name, env: env}, base}, nil
+}
+
+// Find a --profile flag in command line arguments.
+func profileFromArgs(args []string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		flag := strings.TrimLeft(arg, "-")
+		if flag == arg {
+			continue
+		}
+		if value, ok := strings.CutPrefix(flag, profileFlag+"="); ok {
+			return value, true
+		}
+		if flag == profileFlag && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// Environment of a named profile, reporting the profile in its source.
+type profileEnvironment struct {
+	name string
+	env  Environment
+}
+
+// Implement the Environment interface.
+func (p profileEnvironment) LookupEnv(key string) (string, bool) {
+	return p.env.LookupEnv(key)
+}
+
+// Return the kind of source a key is read from.
+func (p profileEnvironment) SourceOf(key string) string {
+	return "profile:" + p.name
+}
+
+// Does the profile hold key as a secret?
+func (p profileEnvironment) IsSecret(key string) bool {
+	return isSecretKey(p.env, key)
+}
+
+// Return the name a key is stored under.
+func (p profileEnvironment) QualifyKey(key string) string {
+	return qualifyKey(p.env, key)
+}
+
+// Select a profile, layer it over base, and record it as the active profile of r.
+// Convenience for calling Profiles.Select(), Profiles.Environment() and Registry.SetProfile().
+func (p Profiles) Apply(r *Registry, base Environment, args []string) (string, Environment, error) {
+	name, err := p.Select(base, args)
+	if err != nil {
+		return "", nil, err
+	}
+	env, err := p.Environment(name, base)
+	if err != nil {
+		return "", nil, err
+	}
+	r.SetProfile(name)
+	return name, env, nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfiles_Select(t *testing.T) {
+	t.Parallel()
+
+	profiles := Profiles{
+		"dev":  MapEnvironment{},
+		"prod": MapEnvironment{},
+	}
+
+	type Test struct {
+		name      string
+		env       Environment
+		args      []string
+		expect    string
+		expectErr error
+	}
+
+	tests := []Test{
+		{name: "Select() should default to no profile", env: MapEnvironment{}, expect: ""},
+		{name: "Select() should read APP_PROFILE", env: MapEnvironment{ProfileVariable: "dev"}, expect: "dev"},
+		{name: "Select() should read --profile=", env: MapEnvironment{}, args: []string{"--profile=prod"}, expect: "prod"},
+		{name: "Select() should read -profile", env: MapEnvironment{}, args: []string{"-v", "-profile", "prod"}, expect: "prod"},
+		{name: "Select() should prefer the flag", env: MapEnvironment{ProfileVariable: "dev"}, args: []string{"--profile", "prod"}, expect: "prod"},
+		{name: "Select() should ignore arguments after --", env: MapEnvironment{}, args: []string{"--", "--profile=prod"}, expect: ""},
+		{name: "Select() should fail on unknown profiles", env: MapEnvironment{ProfileVariable: "staging"}, expectErr: ErrUnknownProfile},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := profiles.Select(test.env, test.args)
+			if !errors.Is(err, test.expectErr) {
+				t.Fatalf("Profiles.Select() error = %v; expect %v", err, test.expectErr)
+			}
+			if result != test.expect {
+				t.Errorf("Profiles.Select() = %q; expect %q", result, test.expect)
+			}
+		})
+	}
+}
+
+func TestProfiles_Apply(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env.prod"), []byte("HTTP_HOST=example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := LoadProfiles(dir, "dev", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := MapEnvironment{"HTTP_HOST": "localhost", "HTTP_PORT": "5000"}
+	registry := NewRegistry()
+	name, env, err := profiles.Apply(registry, base, []string{"--profile=prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "prod" {
+		t.Errorf("Profiles.Apply() = %q; expect %q", name, "prod")
+	}
+	if result := registry.Profile(); result != "prod" {
+		t.Errorf("Registry.Profile() = %q; expect %q", result, "prod")
+	}
+
+	host := Resolve(ConvString(EnvironmentVariable("HTTP_HOST").In(env), false))
+	if host.Value != "example.com" || host.Origin.String() != "profile:prod:HTTP_HOST" {
+		t.Errorf("HTTP_HOST = %q from %q; expect %q from %q", host.Value, host.Origin, "example.com", "profile:prod:HTTP_HOST")
+	}
+
+	port := Resolve(ConvInt(EnvironmentVariable("HTTP_PORT").In(env)))
+	if port.Value != 5000 || port.Origin.String() != "map:HTTP_PORT" {
+		t.Errorf("HTTP_PORT = %d from %q; expect %d from %q", port.Value, port.Origin, 5000, "map:HTTP_PORT")
+	}
+
+	if _, _, err := profiles.Apply(NewRegistry(), base, []string{"--profile=staging"}); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("Profiles.Apply() error = %v; expect %v", err, ErrUnknownProfile)
+	}
+}
+
+func TestRegistry_Profile(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.SetProfile("prod")
+	if result := registry.Profile(); result != "prod" {
+		t.Errorf("Registry.Profile() = %q; expect %q", result, "prod")
+	}
+}
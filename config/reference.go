@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Human readable names of setting types.
+var typeNames = map[reflect.Type]string{
+	reflect.TypeFor[*url.URL]():   "url",
+	reflect.TypeFor[slog.Level](): "level",
+	reflect.TypeFor[[]string]():   "list",
+}
+
+// Return a human readable name for a setting type.
+func typeName(t reflect.Type) string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	// Report the type wrapped by a Secret.
+	if t.Implements(reflect.TypeFor[secret]()) {
+		if method, ok := t.MethodByName("Reveal"); ok {
+			return typeName(method.Type.Out(0))
+		}
+	}
+	return t.String()
+}
+
+// Write a Markdown reference of every registered setting.
+func (r *Registry) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| Key | Type | Default | Description |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, entry := range r.Entries() {
+		value := entry.Default
+		if entry.Secret {
+			value = ""
+		}
+		fmt.Fprintf(
+			&b,
+			"| `%s` | %s | %s | %s |\n",
+			entry.Key,
+			markdownCell(referenceType(entry)),
+			markdownDefault(value),
+			markdownCell(entry.Description),
+		)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Write a sample .env file containing every registered setting.
+// Settings are set to their default value, secrets are left empty.
+func (r *Registry) WriteEnvFile(w io.Writer) error {
+	var b strings.Builder
+	for i, entry := range r.Entries() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if entry.Description != "" {
+			fmt.Fprintf(&b, "# %s\n", entry.Description)
+		}
+		fmt.Fprintf(&b, "# Type: %s\n", referenceType(entry))
+		value := entry.Default
+		if entry.Secret {
+			value = ""
+		}
+		fmt.Fprintf(&b, "%s=%s\n", entry.Key, envFileValue(value))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Write a --help style listing of every registered setting.
+func (r *Registry) WriteUsage(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Environment variables:")
+	for _, entry := range r.Entries() {
+		description := entry.Description
+		if entry.Default != "" && !entry.Secret {
+			description = strings.TrimSpace(fmt.Sprintf("%s (default %s)", description, entry.Default))
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", entry.Key, referenceType(entry), description)
+	}
+	return tw.Flush()
+}
+
+// Return the type of an entry for use in a reference.
+func referenceType(entry Entry) string {
+	if entry.Secret {
+		return entry.Type + ", secret"
+	}
+	return entry.Type
+}
+
+// Escape text for use in a Markdown table cell.
+func markdownCell(text string) string {
+	return strings.ReplaceAll(text, "|", "\\|")
+}
+
+// Format a default value for use in a Markdown table cell.
+func markdownDefault(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "`" + markdownCell(value) + "`"
+}
+
+// Quote a value for use in a .env file if required.
+func envFileValue(value string) string {
+	if strings.ContainsAny(value, " \t\r\n\"'#$\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Create a registry of described settings for reference tests.
+func newReferenceRegistry() *Registry {
+	registry := NewRegistry()
+	Define(registry, "HTTP_PORT", "Port to listen on.", ConvInt(EnvironmentVariable("HTTP_PORT")), Fallback(80))
+	Define(registry, "GREETING", "Greeting | shown on the home page.", ConvString(EnvironmentVariable("GREETING"), false), Fallback("hello world"))
+	Define(registry, "CLIENT_SECRET", "MSAL client secret.", AsSecret(ConvString(EnvironmentVariable("CLIENT_SECRET"), false)), Fallback(NewSecret("changeme")))
+	return registry
+}
+
+func TestTypeName(t *testing.T) {
+	type Test struct {
+		name   string
+		t      reflect.Type
+		expect string
+	}
+
+	tests := []Test{
+		{name: "typeName() should be \"int\"", t: reflect.TypeFor[int](), expect: "int"},
+		{name: "typeName() should be \"url\"", t: reflect.TypeFor[*url.URL](), expect: "url"},
+		{name: "typeName() should be \"level\"", t: reflect.TypeFor[slog.Level](), expect: "level"},
+		{name: "typeName() should be \"list\"", t: reflect.TypeFor[[]string](), expect: "list"},
+		{name: "typeName() should be \"bool\"", t: reflect.TypeFor[Secret[bool]](), expect: "bool"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := typeName(test.t)
+			if result != test.expect {
+				t.Errorf("typeName() = %q; expect %q", result, test.expect)
+			}
+		})
+	}
+}
+
+func TestRegistry_WriteMarkdown(t *testing.T) {
+	var b strings.Builder
+	if err := newReferenceRegistry().WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := "| Key | Type | Default | Description |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `HTTP_PORT` | int | `80` | Port to listen on. |\n" +
+		"| `GREETING` | string | `hello world` | Greeting \\| shown on the home page. |\n" +
+		"| `CLIENT_SECRET` | string, secret |  | MSAL client secret. |\n"
+	if b.String() != expect {
+		t.Errorf("Registry.WriteMarkdown() = %q; expect %q", b.String(), expect)
+	}
+}
+
+func TestRegistry_WriteEnvFile(t *testing.T) {
+	var b strings.Builder
+	if err := newReferenceRegistry().WriteEnvFile(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := "# Port to listen on.\n# Type: int\nHTTP_PORT=80\n" +
+		"\n# Greeting | shown on the home page.\n# Type: string\nGREETING=\"hello world\"\n" +
+		"\n# MSAL client secret.\n# Type: string, secret\nCLIENT_SECRET=\n"
+	if b.String() != expect {
+		t.Errorf("Registry.WriteEnvFile() = %q; expect %q", b.String(), expect)
+	}
+}
+
+func TestRegistry_WriteUsage(t *testing.T) {
+	var b strings.Builder
+	if err := newReferenceRegistry().WriteUsage(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expect := range []string{"HTTP_PORT", "Port to listen on. (default 80)", "string, secret"} {
+		if !strings.Contains(b.String(), expect) {
+			t.Errorf("Registry.WriteUsage() = %q; expect to contain %q", b.String(), expect)
+		}
+	}
+	if strings.Contains(b.String(), redacted) {
+		t.Errorf("Registry.WriteUsage() = %q; expect secret defaults to be omitted", b.String())
+	}
+}
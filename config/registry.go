@@ -0,0 +1,184 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"text/tabwriter"
+)
+
+// Effective configuration of a registered setting.
+type Entry struct {
+	Key         string `json:"key"`                   // Registered setting key.
+	Description string `json:"description,omitempty"` // What the setting controls.
+	Type        string `json:"type"`                  // Human readable value type, e.g. "int".
+	Value       string `json:"value"`                 // Formatted value, redacted for secrets.
+	Set         bool   `json:"set"`                   // Was the value resolved?
+	Source      string `json:"source"`                // Origin of the value, e.g. "env:HTTP_PORT".
+	Default     string `json:"default,omitempty"`     // Formatted fallback value, redacted for secrets.
+	Secret      bool   `json:"secret"`                // Is the value a Secret, or read from a secret source?
+}
+
+// Collection of settings used to report the effective configuration.
+// Create with NewRegistry().
+type Registry struct {
+	profile string                  // Active configuration profile.
+	keys    []string                // Keys in registration order.
+	entries map[string]func() Entry // Entry providers by key.
+	mutex   sync.Mutex              // Sync access to keys and entries.
+}
+
+// Create a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		entries: map[string]func() Entry{},
+	}
+}
+
+// Default registry.
+var DefaultRegistry *Registry = NewRegistry()
+
+// Resolve a setting and record it in the registry under key.
+func Register[T any](r *Registry, key string, resolvers ...Resolver[T]) T {
+	return Define(r, key, "", resolvers...)
+}
+
+// Resolve a described setting and record it in the registry under key.
+// The description is included in the generated configuration reference.
+func Define[T any](r *Registry, key string, description string, resolvers ...Resolver[T]) T {
+	setting := Resolve(resolvers...)
+	r.add(key, func() Entry {
+		return newEntry(key, description, setting)
+	})
+	return setting.Value
+}
+
+// Create a LiveSetting and record it in the registry under key.
+// Entries always report the current value of the setting.
+func RegisterLive[T any](r *Registry, key string, resolvers ...Resolver[T]) *LiveSetting[T] {
+	return DefineLive(r, key, "", resolvers...)
+}
+
+// Create a described LiveSetting and record it in the registry under key.
+// Entries always report the current value of the setting.
+func DefineLive[T any](r *Registry, key string, description string, resolvers ...Resolver[T]) *LiveSetting[T] {
+	live := NewLiveSetting(resolvers...)
+	r.add(key, func() Entry {
+		return newEntry(key, description, live.Setting())
+	})
+	return live
+}
+
+// Record the active configuration profile.
+func (r *Registry) SetProfile(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.profile = name
+}
+
+// Return the active configuration profile.
+func (r *Registry) Profile() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.profile
+}
+
+// Add an entry provider, replacing any existing provider with the same key.
+func (r *Registry) add(key string, entry func() Entry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.entries[key]; !ok {
+		r.keys = append(r.keys, key)
+	}
+	r.entries[key] = entry
+}
+
+// Return the effective configuration in registration order.
+func (r *Registry) Entries() []Entry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entries := make([]Entry, 0, len(r.keys))
+	for _, key := range r.keys {
+		entries = append(entries, r.entries[key]())
+	}
+	return entries
+}
+
+// Write the effective configuration as a table.
+func (r *Registry) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if profile := r.Profile(); profile != "" {
+		fmt.Fprintf(tw, "PROFILE: %s\n", profile)
+	}
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE\tDEFAULT")
+	for _, entry := range r.Entries() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", entry.Key, entry.Value, entry.Source, entry.Default)
+	}
+	return tw.Flush()
+}
+
+// Effective configuration written by Registry.WriteJSON().
+type Report struct {
+	Profile  string  `json:"profile,omitempty"` // Active configuration profile.
+	Settings []Entry `json:"settings"`          // Entries in registration order.
+}
+
+// Write the effective configuration as a JSON Report object,
+// holding the active profile and the entries in registration order.
+func (r *Registry) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Report{
+		Profile:  r.Profile(),
+		Settings: r.Entries(),
+	})
+}
+
+// Implement the slog.LogValuer interface.
+func (r *Registry) LogValue() slog.Value {
+	entries := r.Entries()
+	attrs := make([]slog.Attr, 0, len(entries)+1)
+	if profile := r.Profile(); profile != "" {
+		attrs = append(attrs, slog.String("profile", profile))
+	}
+	for _, entry := range entries {
+		attrs = append(attrs, slog.Group(
+			entry.Key,
+			slog.String("value", entry.Value),
+			slog.String("source", entry.Source),
+		))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Create an entry from a resolved setting.
+func newEntry[T any](key string, description string, setting Setting[T]) Entry {
+	// Values read from secret sources are redacted even if they are not wrapped with AsSecret().
+	_, isSecret := any(setting.Value).(secret)
+	isSecret = isSecret || setting.Origin.Secret
+	entry := Entry{
+		Key:         key,
+		Description: description,
+		Type:        typeName(reflect.TypeFor[T]()),
+		Set:         setting.Set,
+		Source:      setting.Origin.String(),
+		Secret:      isSecret,
+	}
+	switch {
+	case setting.Set && isSecret:
+		entry.Value = redacted
+	case setting.Set:
+		entry.Value = fmt.Sprint(setting.Value)
+	}
+	if setting.Default != nil {
+		entry.Default = fmt.Sprint(*setting.Default)
+	}
+	return entry
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Entries(t *testing.T) {
+	t.Setenv("HTTP_PORT", "5000")
+	t.Setenv("CLIENT_SECRET", "hunter2")
+
+	registry := NewRegistry()
+	Register(registry, "HTTP_PORT", ConvInt(EnvironmentVariable("HTTP_PORT")), Fallback(80))
+	Register(registry, "HTTP_HOST", ConvString(EnvironmentVariable("HTTP_HOST"), false), Fallback("localhost"))
+	Register(registry, "CLIENT_SECRET", AsSecret(ConvString(NewSecretVariable("CLIENT_SECRET"), false)))
+
+	expect := []Entry{
+		{Key: "HTTP_PORT", Type: "int", Value: "5000", Set: true, Source: "env:HTTP_PORT", Default: "80"},
+		{Key: "HTTP_HOST", Type: "string", Value: "localhost", Set: true, Source: "default", Default: "localhost"},
+		{Key: "CLIENT_SECRET", Type: "string", Value: redacted, Set: true, Source: "env:CLIENT_SECRET", Secret: true},
+	}
+
+	result := registry.Entries()
+	if len(result) != len(expect) {
+		t.Fatalf("Registry.Entries() = %v; expect %v", result, expect)
+	}
+	for i := range expect {
+		if result[i] != expect[i] {
+			t.Errorf("Registry.Entries()[%d] = %+v; expect %+v", i, result[i], expect[i])
+		}
+	}
+}
+
+func TestRegistry_RegisterLive(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "info")
+
+	registry := NewRegistry()
+	level := RegisterLive(registry, "LOG_LEVEL", ConvLevel(EnvironmentVariable("LOG_LEVEL")))
+
+	t.Setenv("LOG_LEVEL", "debug")
+	level.Reload()
+
+	result := registry.Entries()[0].Value
+	if result != "DEBUG" {
+		t.Errorf("Registry.Entries()[0].Value = %q; expect %q", result, "DEBUG")
+	}
+}
+
+func TestRegistry_Write(t *testing.T) {
+	t.Setenv("CLIENT_SECRET", "hunter2")
+
+	registry := NewRegistry()
+	Register(registry, "CLIENT_SECRET", AsSecret(ConvString(EnvironmentVariable("CLIENT_SECRET"), false)))
+
+	var table bytes.Buffer
+	if err := registry.WriteTable(&table); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(table.String(), "hunter2") {
+		t.Errorf("Registry.WriteTable() = %q; expect secret to be redacted", table.String())
+	}
+
+	var data bytes.Buffer
+	if err := registry.WriteJSON(&data); err != nil {
+		t.Fatal(err)
+	}
+	var report Report
+	if err := json.Unmarshal(data.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Settings) != 1 || report.Settings[0].Value != redacted {
+		t.Errorf("Registry.WriteJSON() = %s; expect secret to be redacted", data.String())
+	}
+}
+
+func TestRegistry_SecretSources(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "CLIENT_SECRET"), []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyring := NewKeyring()
+	if err := keyring.Add("2025", generateTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := keyring.Encrypt("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type Test struct {
+		name     string
+		resolver Resolver[string]
+	}
+
+	// None of the values are wrapped with AsSecret().
+	tests := []Test{
+		{"Secret directory values should be redacted", ConvString(NewSecretVariable("CLIENT_SECRET", dir).In(MapEnvironment{}), false)},
+		{"KEY_FILE values should be redacted", ConvString(NewSecretVariable("CLIENT_SECRET").In(MapEnvironment{"CLIENT_SECRET_FILE": filepath.Join(dir, "CLIENT_SECRET")}), false)},
+		{"Encrypted environment values should be redacted", ConvString(NewVariable(Decrypt(MapEnvironment{"CLIENT_SECRET": encrypted}, keyring), "CLIENT_SECRET"), false)},
+		{"Decrypted values should be redacted", Decrypted(keyring, ConvString(NewVariable(MapEnvironment{"CLIENT_SECRET": encrypted}, "CLIENT_SECRET"), false))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := NewRegistry()
+			if value := Register(registry, "CLIENT_SECRET", test.resolver); value != "hunter2" {
+				t.Fatalf("Register() = %q; expect %q", value, "hunter2")
+			}
+
+			var table, data bytes.Buffer
+			if err := registry.WriteTable(&table); err != nil {
+				t.Fatal(err)
+			}
+			if err := registry.WriteJSON(&data); err != nil {
+				t.Fatal(err)
+			}
+			for _, output := range []string{table.String(), data.String()} {
+				if strings.Contains(output, "hunter2") {
+					t.Errorf("registry output = %q; expect secret to be redacted", output)
+				}
+			}
+			if entry := registry.Entries()[0]; !entry.Secret || entry.Value != redacted {
+				t.Errorf("Registry.Entries()[0] = %+v; expect a redacted secret", entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Defines a value that can be reloaded, e.g. a LiveSetting.
+type Reloader interface {
+	Reload()
+}
+
+// Reload every reloader.
+func reloadAll(reloaders []Reloader) {
+	for _, reloader := range reloaders {
+		reloader.Reload()
+	}
+}
+
+// Reload the reloaders every time the process receives SIGHUP.
+// Stops listening when the context is done.
+func ReloadOnSignal(ctx context.Context, reloaders ...Reloader) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				reloadAll(reloaders)
+			}
+		}
+	}()
+}
+
+// Reload the reloaders every time the file at path changes.
+// The file is checked for changes every interval.
+// Reloaders are reloaded in order, so pass a FileEnvironment before the settings reading it.
+// Stops watching when the context is done.
+func ReloadOnFileChange(ctx context.Context, path string, interval time.Duration, reloaders ...Reloader) {
+	previous := fileVersion(path)
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				current := fileVersion(path)
+				if current != previous {
+					previous = current
+					reloadAll(reloaders)
+				}
+			}
+		}
+	}()
+}
+
+// Identifies a version of a file's contents.
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// Return the current version of the file at path.
+func fileVersion(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{
+		exists:  true,
+		size:    info.Size(),
+		modTime: info.ModTime(),
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Reloader that reports every reload on a channel.
+type testReloader chan struct{}
+
+func (r testReloader) Reload() {
+	r <- struct{}{}
+}
+
+// Wait for a reload or fail the test.
+func (r testReloader) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-r:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+}
+
+func TestReloadOnFileChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings")
+	if err := os.WriteFile(path, []byte("a"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	reloader := make(testReloader, 1)
+	ReloadOnFileChange(t.Context(), path, 10*time.Millisecond, reloader)
+
+	if err := os.WriteFile(path, []byte("ab"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	reloader.wait(t)
+}
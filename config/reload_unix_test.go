@@ -0,0 +1,19 @@
+//go:build unix
+
+package config
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	reloader := make(testReloader, 1)
+	ReloadOnSignal(t.Context(), reloader)
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	reloader.wait(t)
+}
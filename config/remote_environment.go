@@ -0,0 +1,294 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RemoteEnvironment configuration.
+type remoteEnvironmentConfig struct {
+	client    *http.Client
+	timeout   time.Duration
+	cacheFile string
+	keyPrefix string
+}
+
+// RemoteEnvironment option.
+type RemoteEnvironmentOption func(cfg *remoteEnvironmentConfig)
+
+// Environment fetched from an HTTP key-value endpoint returning Consul KV style JSON,
+// e.g. [{"Key": "app/HTTP_PORT", "Value": "<base64 value>"}].
+// Create with NewRemoteEnvironment().
+type RemoteEnvironment struct {
+	url       string
+	client    *http.Client
+	timeout   time.Duration
+	cacheFile string
+	keyPrefix string
+	values    map[string]string // Current values by key.
+	etag      string            // ETag of the current values.
+	source    string            // Where the current values were loaded from.
+	mutex     sync.RWMutex      // Sync access to values, etag and source.
+}
+
+// Contents of the last-known-good cache file.
+type remoteCache struct {
+	ETag   string            `json:"etag"`
+	Values map[string]string `json:"values"`
+}
+
+// Single key-value pair returned by the endpoint.
+type remotePair struct {
+	Key   string  `json:"Key"`
+	Value *string `json:"Value"`
+}
+
+// Optional HTTP client. Defaults to http.DefaultClient.
+func WithHTTPClient(client *http.Client) RemoteEnvironmentOption {
+	return func(cfg *remoteEnvironmentConfig) {
+		cfg.client = client
+	}
+}
+
+// Optional request timeout. Defaults to 10 seconds.
+func WithTimeout(timeout time.Duration) RemoteEnvironmentOption {
+	return func(cfg *remoteEnvironmentConfig) {
+		cfg.timeout = timeout
+	}
+}
+
+// Optional file used to cache the last fetched values.
+// The cache is used when the endpoint cannot be reached at startup.
+func WithCacheFile(path string) RemoteEnvironmentOption {
+	return func(cfg *remoteEnvironmentConfig) {
+		cfg.cacheFile = path
+	}
+}
+
+// Optional prefix removed from remote keys, e.g. "app/".
+// Keys without the prefix are ignored.
+func WithKeyPrefix(prefix string) RemoteEnvironmentOption {
+	return func(cfg *remoteEnvironmentConfig) {
+		cfg.keyPrefix = prefix
+	}
+}
+
+// Create a new RemoteEnvironment and fetch its initial values.
+// If the endpoint cannot be reached the cache file is used instead,
+// an error is only returned if neither is available.
+func NewRemoteEnvironment(ctx context.Context, url string, options ...RemoteEnvironmentOption) (*RemoteEnvironment, error) {
+	// Init default config.
+	cfg := &remoteEnvironmentConfig{
+		client:  http.DefaultClient,
+		timeout: 10 * time.Second,
+	}
+	// Apply options to config.
+	for _, option := range options {
+		option(cfg)
+	}
+
+	r := &RemoteEnvironment{
+		url:       url,
+		client:    cfg.client,
+		timeout:   cfg.timeout,
+		cacheFile: cfg.cacheFile,
+		keyPrefix: cfg.keyPrefix,
+		values:    map[string]string{},
+	}
+
+	_, err := r.Refresh(ctx)
+	if err == nil {
+		return r, nil
+	}
+	if cacheErr := r.loadCache(); cacheErr != nil {
+		return nil, fmt.Errorf("NewRemoteEnvironment: %w", errors.Join(err, cacheErr))
+	}
+	slog.Warn(
+		"using cached remote configuration",
+		slog.String("url", url),
+		slog.Any("err", err),
+	)
+	return r, nil
+}
+
+// Fetch the latest values from the endpoint.
+// Returns true if the values have changed.
+func (r *RemoteEnvironment) Refresh(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return false, fmt.Errorf("RemoteEnvironment.Refresh(): %w", err)
+	}
+	r.mutex.RLock()
+	if r.etag != "" {
+		req.Header.Set("If-None-Match", r.etag)
+	}
+	r.mutex.RUnlock()
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("RemoteEnvironment.Refresh(): %w", err)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusNotModified:
+		r.mutex.Lock()
+		r.source = "remote"
+		r.mutex.Unlock()
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("RemoteEnvironment.Refresh(): unexpected status %s", res.Status)
+	}
+
+	var pairs []remotePair
+	if err := json.NewDecoder(res.Body).Decode(&pairs); err != nil {
+		return false, fmt.Errorf("RemoteEnvironment.Refresh(): %w", err)
+	}
+	values, err := r.decode(pairs)
+	if err != nil {
+		return false, fmt.Errorf("RemoteEnvironment.Refresh(): %w", err)
+	}
+
+	r.mutex.Lock()
+	changed := !maps.Equal(r.values, values)
+	r.values = values
+	r.etag = res.Header.Get("ETag")
+	r.source = "remote"
+	cache := remoteCache{ETag: r.etag, Values: values}
+	r.mutex.Unlock()
+
+	if err := r.saveCache(cache); err != nil {
+		slog.Warn(
+			"failed to cache remote configuration",
+			slog.String("path", r.cacheFile),
+			slog.Any("err", err),
+		)
+	}
+	return changed, nil
+}
+
+// Refresh the values every interval and reload the reloaders when they change.
+// Stops polling when the context is done.
+func (r *RemoteEnvironment) Poll(ctx context.Context, interval time.Duration, reloaders ...Reloader) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				changed, err := r.Refresh(ctx)
+				if err != nil {
+					slog.Warn(
+						"failed to refresh remote configuration",
+						slog.String("url", r.url),
+						slog.Any("err", err),
+					)
+					continue
+				}
+				if changed {
+					reloadAll(reloaders)
+				}
+			}
+		}
+	}()
+}
+
+// Implement the Environment interface.
+func (r *RemoteEnvironment) LookupEnv(key string) (string, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	value, ok := r.values[key]
+	return value, ok
+}
+
+// Return the kind of source a key is read from, "remote" or "cache".
+func (r *RemoteEnvironment) SourceOf(key string) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.source
+}
+
+// Convert key-value pairs to values by key.
+func (r *RemoteEnvironment) decode(pairs []remotePair) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, ok := strings.CutPrefix(pair.Key, r.keyPrefix)
+		// Skip keys outside the prefix and folders, which have no value.
+		if !ok || key == "" || pair.Value == nil {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(*pair.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %q: %w", pair.Key, err)
+		}
+		values[key] = string(value)
+	}
+	return values, nil
+}
+
+// Load the last-known-good values from the cache file.
+func (r *RemoteEnvironment) loadCache() error {
+	if r.cacheFile == "" {
+		return errors.New("no cache file configured")
+	}
+	data, err := os.ReadFile(r.cacheFile)
+	if err != nil {
+		return err
+	}
+	var cache remoteCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return fmt.Errorf("invalid cache file %q: %w", r.cacheFile, err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.values = cache.Values
+	r.etag = cache.ETag
+	r.source = "cache"
+	return nil
+}
+
+// Atomically write the values to the cache file.
+func (r *RemoteEnvironment) saveCache(cache remoteCache) error {
+	if r.cacheFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(r.cacheFile), filepath.Base(r.cacheFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), r.cacheFile)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Stand-in for a Consul KV endpoint serving a single value.
+type testKVServer struct {
+	port     atomic.Int64
+	requests atomic.Int64
+}
+
+func (s *testKVServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	port := fmt.Sprint(s.port.Load())
+	etag := `"` + port + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	fmt.Fprintf(
+		w,
+		`[{"Key": "app/", "Value": null}, {"Key": "app/HTTP_PORT", "Value": %q}, {"Key": "other/HTTP_PORT", "Value": %q}]`,
+		base64.StdEncoding.EncodeToString([]byte(port)),
+		base64.StdEncoding.EncodeToString([]byte("1")),
+	)
+}
+
+func TestRemoteEnvironment_Refresh(t *testing.T) {
+	kv := &testKVServer{}
+	kv.port.Store(5000)
+	server := httptest.NewServer(kv)
+	defer server.Close()
+
+	env, err := NewRemoteEnvironment(t.Context(), server.URL, WithKeyPrefix("app/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port := Resolve(ConvInt(EnvironmentVariable("HTTP_PORT").In(env)))
+	if port.Value != 5000 || port.Origin.String() != "remote:HTTP_PORT" {
+		t.Errorf("HTTP_PORT = %d from %q; expect %d from %q", port.Value, port.Origin, 5000, "remote:HTTP_PORT")
+	}
+
+	changed, err := env.Refresh(t.Context())
+	if err != nil || changed {
+		t.Errorf("RemoteEnvironment.Refresh() = %v, %v; expect false, nil", changed, err)
+	}
+
+	kv.port.Store(6000)
+	changed, err = env.Refresh(t.Context())
+	if err != nil || !changed {
+		t.Errorf("RemoteEnvironment.Refresh() = %v, %v; expect true, nil", changed, err)
+	}
+	if value, _ := env.LookupEnv("HTTP_PORT"); value != "6000" {
+		t.Errorf("RemoteEnvironment.LookupEnv() = %q; expect %q", value, "6000")
+	}
+}
+
+func TestRemoteEnvironment_Cache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+
+	kv := &testKVServer{}
+	kv.port.Store(5000)
+	server := httptest.NewServer(kv)
+
+	if _, err := NewRemoteEnvironment(t.Context(), server.URL, WithKeyPrefix("app/"), WithCacheFile(cacheFile)); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	// The endpoint is unavailable, values are loaded from the cache.
+	env, err := NewRemoteEnvironment(t.Context(), server.URL, WithCacheFile(cacheFile), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := Resolve(ConvInt(EnvironmentVariable("HTTP_PORT").In(env)))
+	if port.Value != 5000 || port.Origin.String() != "cache:HTTP_PORT" {
+		t.Errorf("HTTP_PORT = %d from %q; expect %d from %q", port.Value, port.Origin, 5000, "cache:HTTP_PORT")
+	}
+
+	// Without a cache creating the environment fails.
+	if _, err := NewRemoteEnvironment(t.Context(), server.URL, WithTimeout(time.Second)); err == nil {
+		t.Errorf("NewRemoteEnvironment() error = nil; expect an error")
+	}
+}
+
+func TestRemoteEnvironment_Poll(t *testing.T) {
+	kv := &testKVServer{}
+	kv.port.Store(5000)
+	server := httptest.NewServer(kv)
+	defer server.Close()
+
+	env, err := NewRemoteEnvironment(t.Context(), server.URL, WithKeyPrefix("app/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	setting := NewLiveSetting(ConvInt(EnvironmentVariable("HTTP_PORT").In(env)))
+	reloader := make(testReloader, 1)
+	env.Poll(t.Context(), 10*time.Millisecond, setting, reloader)
+
+	kv.port.Store(6000)
+	reloader.wait(t)
+	if result := setting.Get(); result != 6000 {
+		t.Errorf("LiveSetting.Get() = %d; expect %d", result, 6000)
+	}
+}
@@ -14,10 +14,11 @@ type Resolver[T any] func(s Setting[T]) Setting[T]
 // Specify a default setting value.
 func Fallback[T any](value T) Resolver[T] {
 	return func(s Setting[T]) Setting[T] {
+		s.Default = &value
 		if s.Set {
 			return s
 		}
-		return NewSetting(value)
+		return s.resolved(value, Origin{Resolver: "Fallback", Source: "default"})
 	}
 }
 
@@ -38,7 +39,7 @@ func ConvBool(value fmt.Stringer) Resolver[bool] {
 			return s
 		}
 		if parsed, err := strconv.ParseBool(value.String()); err == nil {
-			return NewSetting(parsed)
+			return s.resolved(parsed, originOf("ConvBool", value))
 		}
 		return s
 	}
@@ -51,7 +52,7 @@ func ConvFloat32(value fmt.Stringer) Resolver[float32] {
 			return s
 		}
 		if parsed, err := strconv.ParseFloat(value.String(), 32); err == nil {
-			return NewSetting(float32(parsed))
+			return s.resolved(float32(parsed), originOf("ConvFloat32", value))
 		}
 		return s
 	}
@@ -64,7 +65,7 @@ func ConvFloat64(value fmt.Stringer) Resolver[float64] {
 			return s
 		}
 		if parsed, err := strconv.ParseFloat(value.String(), 64); err == nil {
-			return NewSetting(float64(parsed))
+			return s.resolved(float64(parsed), originOf("ConvFloat64", value))
 		}
 		return s
 	}
@@ -77,7 +78,7 @@ func ConvInt(value fmt.Stringer) Resolver[int] {
 			return s
 		}
 		if parsed, err := strconv.ParseInt(value.String(), 10, 0); err == nil {
-			return NewSetting(int(parsed))
+			return s.resolved(int(parsed), originOf("ConvInt", value))
 		}
 		return s
 	}
@@ -90,7 +91,7 @@ func ConvInt8(value fmt.Stringer) Resolver[int8] {
 			return s
 		}
 		if parsed, err := strconv.ParseInt(value.String(), 10, 8); err == nil {
-			return NewSetting(int8(parsed))
+			return s.resolved(int8(parsed), originOf("ConvInt8", value))
 		}
 		return s
 	}
@@ -103,7 +104,7 @@ func ConvInt16(value fmt.Stringer) Resolver[int16] {
 			return s
 		}
 		if parsed, err := strconv.ParseInt(value.String(), 10, 16); err == nil {
-			return NewSetting(int16(parsed))
+			return s.resolved(int16(parsed), originOf("ConvInt16", value))
 		}
 		return s
 	}
@@ -116,7 +117,7 @@ func ConvInt32(value fmt.Stringer) Resolver[int32] {
 			return s
 		}
 		if parsed, err := strconv.ParseInt(value.String(), 10, 32); err == nil {
-			return NewSetting(int32(parsed))
+			return s.resolved(int32(parsed), originOf("ConvInt32", value))
 		}
 		return s
 	}
@@ -129,7 +130,7 @@ func ConvInt64(value fmt.Stringer) Resolver[int64] {
 			return s
 		}
 		if parsed, err := strconv.ParseInt(value.String(), 10, 64); err == nil {
-			return NewSetting(int64(parsed))
+			return s.resolved(int64(parsed), originOf("ConvInt64", value))
 		}
 		return s
 	}
@@ -143,13 +144,13 @@ func ConvLevel(value fmt.Stringer) Resolver[slog.Level] {
 		}
 		switch strings.ToLower(value.String()) {
 		case "debug":
-			return NewSetting(slog.LevelDebug)
+			return s.resolved(slog.LevelDebug, originOf("ConvLevel", value))
 		case "info":
-			return NewSetting(slog.LevelInfo)
+			return s.resolved(slog.LevelInfo, originOf("ConvLevel", value))
 		case "warn":
-			return NewSetting(slog.LevelWarn)
+			return s.resolved(slog.LevelWarn, originOf("ConvLevel", value))
 		case "error":
-			return NewSetting(slog.LevelError)
+			return s.resolved(slog.LevelError, originOf("ConvLevel", value))
 		}
 		return s
 	}
@@ -164,7 +165,7 @@ func ConvString(value fmt.Stringer, allowEmpty bool) Resolver[string] {
 		if !allowEmpty && value.String() == "" {
 			return s
 		}
-		return NewSetting(value.String())
+		return s.resolved(value.String(), originOf("ConvString", value))
 	}
 }
 
@@ -178,7 +179,7 @@ func ConvStringSlice(value fmt.Stringer, sep string, allowEmpty bool) Resolver[[
 		if !allowEmpty && len(parsed) == 0 {
 			return s
 		}
-		return NewSetting(parsed)
+		return s.resolved(parsed, originOf("ConvStringSlice", value))
 	}
 }
 
@@ -189,7 +190,7 @@ func ConvUint(value fmt.Stringer) Resolver[uint] {
 			return s
 		}
 		if parsed, err := strconv.ParseUint(value.String(), 10, 0); err == nil {
-			return NewSetting(uint(parsed))
+			return s.resolved(uint(parsed), originOf("ConvUint", value))
 		}
 		return s
 	}
@@ -202,7 +203,7 @@ func ConvUint8(value fmt.Stringer) Resolver[uint8] {
 			return s
 		}
 		if parsed, err := strconv.ParseUint(value.String(), 10, 8); err == nil {
-			return NewSetting(uint8(parsed))
+			return s.resolved(uint8(parsed), originOf("ConvUint8", value))
 		}
 		return s
 	}
@@ -215,7 +216,7 @@ func ConvUint16(value fmt.Stringer) Resolver[uint16] {
 			return s
 		}
 		if parsed, err := strconv.ParseUint(value.String(), 10, 16); err == nil {
-			return NewSetting(uint16(parsed))
+			return s.resolved(uint16(parsed), originOf("ConvUint16", value))
 		}
 		return s
 	}
@@ -228,7 +229,7 @@ func ConvUint32(value fmt.Stringer) Resolver[uint32] {
 			return s
 		}
 		if parsed, err := strconv.ParseUint(value.String(), 10, 32); err == nil {
-			return NewSetting(uint32(parsed))
+			return s.resolved(uint32(parsed), originOf("ConvUint32", value))
 		}
 		return s
 	}
@@ -241,7 +242,7 @@ func ConvUint64(value fmt.Stringer) Resolver[uint64] {
 			return s
 		}
 		if parsed, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
-			return NewSetting(uint64(parsed))
+			return s.resolved(uint64(parsed), originOf("ConvUint64", value))
 		}
 		return s
 	}
@@ -254,7 +255,7 @@ func ConvURL(value fmt.Stringer) Resolver[*url.URL] {
 			return s
 		}
 		if parsed, err := url.Parse(value.String()); err == nil {
-			return NewSetting(parsed)
+			return s.resolved(parsed, originOf("ConvURL", value))
 		}
 		return s
 	}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+)
+
+// Text used in place of a secret value.
+const redacted string = "[REDACTED]"
+
+// Implemented by Secret values of any type.
+type secret interface {
+	secret()
+}
+
+// Setting value that must never be printed, logged or serialized.
+// Use Reveal() to access the underlying value.
+type Secret[T any] struct {
+	value T
+}
+
+// Create a new secret with the given value.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Return the underlying secret value.
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+// Mark the value as secret.
+func (s Secret[T]) secret() {}
+
+// Implement the fmt.Stringer interface.
+func (s Secret[T]) String() string {
+	return redacted
+}
+
+// Implement the fmt.GoStringer interface.
+func (s Secret[T]) GoString() string {
+	return redacted
+}
+
+// Implement the slog.LogValuer interface.
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// Implement the json.Marshaler interface.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// Create a resolver that wraps the value of another resolver in a Secret.
+func AsSecret[T any](resolver Resolver[T]) Resolver[Secret[T]] {
+	return func(s Setting[Secret[T]]) Setting[Secret[T]] {
+		if s.Set {
+			return s
+		}
+		inner := resolver(Setting[T]{})
+		if inner.Default != nil {
+			secret := NewSecret(*inner.Default)
+			s.Default = &secret
+		}
+		if !inner.Set {
+			return s
+		}
+		return s.resolved(NewSecret(inner.Value), inner.Origin)
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jrrdcnnlly/core/logging"
+)
+
+func TestSecret_Reveal(t *testing.T) {
+	secret := NewSecret("hunter2")
+	result := secret.Reveal()
+	if result != "hunter2" {
+		t.Errorf("Secret.Reveal() = %q; expect %q", result, "hunter2")
+	}
+}
+
+func TestSecret_Redacted(t *testing.T) {
+	secret := NewSecret("hunter2")
+
+	type Test struct {
+		name   string
+		format func() string
+	}
+
+	tests := []Test{
+		{name: "String() should be redacted", format: func() string { return secret.String() }},
+		{name: "%v should be redacted", format: func() string { return fmt.Sprintf("%v", secret) }},
+		{name: "%+v should be redacted", format: func() string { return fmt.Sprintf("%+v", secret) }},
+		{name: "%#v should be redacted", format: func() string { return fmt.Sprintf("%#v", secret) }},
+		{name: "JSON should be redacted", format: func() string {
+			data, _ := json.Marshal(struct{ Secret Secret[string] }{secret})
+			return string(data)
+		}},
+		{name: "slog should be redacted", format: func() string {
+			var buffer bytes.Buffer
+			slog.New(slog.NewTextHandler(&buffer, nil)).Info("test", slog.Any("secret", secret))
+			return buffer.String()
+		}},
+		{name: "logging.TextHandler should be redacted", format: func() string {
+			var buffer bytes.Buffer
+			slog.New(logging.NewTextHandler(logging.WithWriter(&buffer))).Info("test", slog.Any("secret", secret))
+			return buffer.String()
+		}},
+		{name: "logging.TextHandler groups should be redacted", format: func() string {
+			var buffer bytes.Buffer
+			logger := slog.New(logging.NewTextHandler(logging.WithWriter(&buffer)))
+			logger.With(slog.Any("secret", secret)).Info("test", slog.Group("client", slog.Any("secret", secret)))
+			return buffer.String()
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := test.format()
+			if strings.Contains(result, "hunter2") {
+				t.Errorf("got %q; expect secret to be redacted", result)
+			}
+			if !strings.Contains(result, redacted) {
+				t.Errorf("got %q; expect %q", result, redacted)
+			}
+		})
+	}
+}
+
+func TestAsSecret(t *testing.T) {
+	t.Setenv("TESTING", "hunter2")
+	env := EnvironmentVariable("TESTING")
+	result := Setting[Secret[string]]{}.Resolve(AsSecret(ConvString(env, false)))
+	if result.Reveal() != "hunter2" {
+		t.Errorf("AsSecret() = %q; expect %q", result.Reveal(), "hunter2")
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Suffix of the environment variable holding the path of a secret file.
+const fileSuffix string = "_FILE"
+
+// Secret read from the environment or from a mounted file.
+// Create with NewSecretVariable().
+//
+// The value is looked up in order from:
+//   - The environment variable KEY.
+//   - The file named by the environment variable KEY_FILE.
+//   - The file KEY in each of the secret directories.
+type SecretVariable struct {
+	env  Environment
+	key  string
+	dirs []string
+}
+
+// Create a new SecretVariable.
+// Optional directories are searched for a file named after the key,
+// e.g. "/run/secrets".
+func NewSecretVariable(key string, dirs ...string) SecretVariable {
+	return SecretVariable{
+		env:  OS,
+		key:  key,
+		dirs: dirs,
+	}
+}
+
+// Read the secret from a specific environment instead of the OS environment.
+// Directories are searched for a file named after the key including any environment prefix.
+func (s SecretVariable) In(env Environment) SecretVariable {
+	s.env = env
+	return s
+}
+
+// Return the secret key, including any environment prefix.
+func (s SecretVariable) Key() string {
+	return qualifyKey(s.env, s.key)
+}
+
+// Return the secret value. If the secret is not set an empty string is returned.
+func (s SecretVariable) Get() string {
+	value, _ := s.Lookup()
+	return value
+}
+
+// Return the secret value. If the secret is not set false is returned as the second value.
+// A file named by KEY_FILE that cannot be read is logged and reported as not set, see Load().
+func (s SecretVariable) Lookup() (string, bool) {
+	value, _, ok, err := s.lookup()
+	if err != nil {
+		slog.Warn(
+			"failed to read secret file",
+			slog.String("key", s.Key()),
+			slog.Any("err", err),
+		)
+	}
+	return value, ok
+}
+
+// Return the secret value. If the secret is not set false is returned as the second value.
+// Returns an error if KEY_FILE is set but the file it names cannot be read.
+func (s SecretVariable) Load() (string, bool, error) {
+	value, _, ok, err := s.lookup()
+	return value, ok, err
+}
+
+// Return the kind of source the secret was read from, "env", "file" or "directory".
+func (s SecretVariable) Source() string {
+	_, source, _, _ := s.lookup()
+	return source
+}
+
+// Is the value read from a secret source? Always true, so the Registry redacts it.
+func (s SecretVariable) IsSecret() bool {
+	return true
+}
+
+// Implement the fmt.Stringer interface.
+func (s SecretVariable) String() string {
+	return s.Get()
+}
+
+// Return the secret value and the kind of source it was read from.
+func (s SecretVariable) lookup() (string, string, bool, error) {
+	if value, ok := s.env.LookupEnv(s.key); ok {
+		return value, sourceOf(s.env, s.key), true, nil
+	}
+	if path, ok := s.env.LookupEnv(s.key + fileSuffix); ok {
+		value, err := readSecretFile(path)
+		if err != nil {
+			return "", "file", false, fmt.Errorf("%s%s: %w", s.Key(), fileSuffix, err)
+		}
+		return value, "file", true, nil
+	}
+	for _, dir := range s.dirs {
+		// Missing files are expected, the secret need not be in every directory.
+		if value, err := readSecretFile(filepath.Join(dir, s.Key())); err == nil {
+			return value, "directory", true, nil
+		}
+	}
+	return "", "", false, nil
+}
+
+// Read a secret file, trimming any trailing newlines.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
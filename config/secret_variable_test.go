@@ -0,0 +1,77 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretVariable_Lookup(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "FROM_DIR"), []byte("directory\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("file\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type Test struct {
+		name     string
+		key      string
+		env      map[string]string
+		expect   string
+		expectOk bool
+	}
+
+	tests := []Test{
+		{name: "Lookup() should read the environment", key: "FROM_ENV", env: map[string]string{"FROM_ENV": "environment"}, expect: "environment", expectOk: true},
+		{name: "Lookup() should read KEY_FILE", key: "FROM_FILE", env: map[string]string{"FROM_FILE_FILE": filepath.Join(dir, "secret.txt")}, expect: "file", expectOk: true},
+		{name: "Lookup() should read the directory", key: "FROM_DIR", expect: "directory", expectOk: true},
+		{name: "Lookup() should prefer the environment", key: "FROM_DIR", env: map[string]string{"FROM_DIR": "environment"}, expect: "environment", expectOk: true},
+		{name: "Lookup() should fail on a missing file", key: "MISSING", env: map[string]string{"MISSING_FILE": filepath.Join(dir, "missing")}, expect: "", expectOk: false},
+		{name: "Lookup() should fail when unset", key: "UNSET", expect: "", expectOk: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+			result, ok := NewSecretVariable(test.key, dir).Lookup()
+			if result != test.expect || ok != test.expectOk {
+				t.Errorf("SecretVariable.Lookup() = %q, %v; expect %q, %v", result, ok, test.expect, test.expectOk)
+			}
+		})
+	}
+}
+
+func TestSecretVariable_Load(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(previous)
+
+	env := MapEnvironment{"CLIENT_SECRET_FILE": filepath.Join(t.TempDir(), "missing")}
+	secret := NewSecretVariable("CLIENT_SECRET").In(env)
+
+	// A mistyped KEY_FILE path is an error, not a missing setting.
+	if _, ok, err := secret.Load(); ok || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("SecretVariable.Load() = %v, %v; expect false, %v", ok, err, fs.ErrNotExist)
+	}
+	if _, ok := secret.Lookup(); ok {
+		t.Errorf("SecretVariable.Lookup() = true; expect false for an unreadable file")
+	}
+	if !strings.Contains(logs.String(), "failed to read secret file") {
+		t.Errorf("logs = %q; expect the unreadable file to be logged", logs.String())
+	}
+
+	// Missing files in the secret directories are not errors.
+	if _, ok, err := NewSecretVariable("CLIENT_SECRET", t.TempDir()).In(MapEnvironment{}).Load(); ok || err != nil {
+		t.Errorf("SecretVariable.Load() = %v, %v; expect false, <nil>", ok, err)
+	}
+}
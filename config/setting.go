@@ -1,9 +1,13 @@
 package config
 
+import "fmt"
+
 // Application setting.
 type Setting[T any] struct {
-	Value T
-	Set   bool
+	Value   T
+	Set     bool
+	Origin  Origin // Where the value was resolved from.
+	Default *T     // Fallback value, if one was specified.
 }
 
 // Create a new application setting with the given value.
@@ -14,6 +18,16 @@ func NewSetting[T any](value T) Setting[T] {
 	}
 }
 
+// Resolve a setting from one or more resolvers.
+// Unlike Setting.Resolve() the whole setting is returned, including its origin.
+func Resolve[T any](resolvers ...Resolver[T]) Setting[T] {
+	var s Setting[T]
+	for _, resolver := range resolvers {
+		s = resolver(s)
+	}
+	return s
+}
+
 // Resolve the setting value from one or more resolvers.
 func (s Setting[T]) Resolve(resolvers ...Resolver[T]) T {
 	for _, resolver := range resolvers {
@@ -21,3 +35,17 @@ func (s Setting[T]) Resolve(resolvers ...Resolver[T]) T {
 	}
 	return s.Value
 }
+
+// Return a copy of the setting set to value, parsed by the named resolver from source.
+// For use by resolvers defined outside this package, so their values record an origin.
+func (s Setting[T]) Resolved(value T, resolver string, source fmt.Stringer) Setting[T] {
+	return s.resolved(value, originOf(resolver, source))
+}
+
+// Return a copy of the setting set to value from origin.
+func (s Setting[T]) resolved(value T, origin Origin) Setting[T] {
+	s.Value = value
+	s.Set = true
+	s.Origin = origin
+	return s
+}
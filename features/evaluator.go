@@ -0,0 +1,80 @@
+package features
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jrrdcnnlly/core/logging"
+	"github.com/jrrdcnnlly/core/sessions"
+)
+
+// Evaluates feature flags for sessions.
+// Create with NewEvaluator().
+type Evaluator struct {
+	flags func() Flags
+}
+
+// Create a new Evaluator reading flag definitions from flags,
+// e.g. the Get method of a config.LiveSetting[Flags].
+func NewEvaluator(flags func() Flags) *Evaluator {
+	return &Evaluator{
+		flags: flags,
+	}
+}
+
+// Is the named feature enabled for the session?
+// Authenticated sessions are assigned by user ID, anonymous sessions by session ID.
+// A nil session only gets features rolled out to everyone.
+// Every evaluation is logged at debug level with the logger in the context.
+func (e *Evaluator) Enabled(ctx context.Context, name string, session *sessions.Session) bool {
+	logger := logging.FromContextOrDefault(ctx)
+
+	flag, ok := e.flags()[name]
+	if !ok {
+		logger.Debug(
+			"feature flag evaluated",
+			slog.Group(
+				"flag",
+				slog.String("name", name),
+				slog.Bool("enabled", false),
+				slog.String("reason", string(ReasonUnknown)),
+			),
+		)
+		return false
+	}
+
+	var subject, userID, username string
+	if session != nil {
+		userID, username = session.UserID, session.Username
+		subject = userID
+		if subject == "" {
+			subject = session.ID()
+		}
+	}
+
+	enabled, reason := flag.evaluate(name, subject, userID, username)
+	if session == nil && reason == ReasonRollout {
+		enabled = flag.Percentage >= 100
+	}
+
+	logger.Debug(
+		"feature flag evaluated",
+		slog.Group(
+			"flag",
+			slog.String("name", name),
+			slog.Bool("enabled", enabled),
+			slog.String("reason", string(reason)),
+		),
+	)
+	return enabled
+}
+
+// Is the named feature enabled for the session in the context?
+// If the context has no session only features rolled out to everyone are enabled.
+func (e *Evaluator) EnabledFromContext(ctx context.Context, name string) bool {
+	session, err := sessions.FromContext(ctx)
+	if err != nil {
+		session = nil
+	}
+	return e.Enabled(ctx, name, session)
+}
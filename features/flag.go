@@ -0,0 +1,98 @@
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+
+	"github.com/jrrdcnnlly/core/config"
+)
+
+// Number of rollout buckets, allowing percentages with two decimal places.
+const buckets uint32 = 10000
+
+// Feature flag definition.
+//
+// A flag is off for everyone unless Enabled is set. When enabled, targeted users
+// always get the feature and everyone else is assigned deterministically by percentage.
+// In JSON a flag may also be the shorthand true (on for everyone) or false (off).
+type Flag struct {
+	Enabled    bool     `json:"enabled"`              // Kill switch, the flag is off when false.
+	UserIDs    []string `json:"userIds,omitempty"`    // Users that always get the feature.
+	Usernames  []string `json:"usernames,omitempty"`  // Usernames that always get the feature.
+	Percentage float64  `json:"percentage,omitempty"` // Percentage (0-100) of other users that get the feature.
+}
+
+// Feature flag definitions by name.
+type Flags map[string]Flag
+
+// Implement the json.Unmarshaler interface, accepting boolean shorthands.
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*f = Flag{Enabled: enabled}
+		if enabled {
+			f.Percentage = 100
+		}
+		return nil
+	}
+	// Alias the type to avoid recursing into this method.
+	type flag Flag
+	var parsed flag
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	if parsed.Percentage < 0 || parsed.Percentage > 100 {
+		return fmt.Errorf("percentage %v is not between 0 and 100", parsed.Percentage)
+	}
+	*f = Flag(parsed)
+	return nil
+}
+
+// Reason a flag evaluated the way it did.
+type Reason string
+
+const (
+	ReasonUnknown  Reason = "unknown"  // The flag is not defined.
+	ReasonDisabled Reason = "disabled" // The flag is switched off.
+	ReasonTargeted Reason = "targeted" // The user is targeted by ID or username.
+	ReasonRollout  Reason = "rollout"  // The user was assigned by percentage.
+)
+
+// Evaluate the flag for a subject, such as a user or session ID.
+func (f Flag) evaluate(name string, subject string, userID string, username string) (bool, Reason) {
+	if !f.Enabled {
+		return false, ReasonDisabled
+	}
+	if (userID != "" && slices.Contains(f.UserIDs, userID)) ||
+		(username != "" && slices.Contains(f.Usernames, username)) {
+		return true, ReasonTargeted
+	}
+	return bucket(name, subject) < uint32(f.Percentage*float64(buckets)/100), ReasonRollout
+}
+
+// Assign a subject to a rollout bucket.
+// Hashing the flag name with the subject keeps assignments independent between flags.
+func bucket(name string, subject string) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	hash.Write([]byte{0})
+	hash.Write([]byte(subject))
+	return hash.Sum32() % buckets
+}
+
+// Create a resolver that returns flag definitions parsed from JSON,
+// e.g. {"new-ui": {"enabled": true, "percentage": 25}, "beta": true}.
+func ConvFlags(value fmt.Stringer) config.Resolver[Flags] {
+	return func(s config.Setting[Flags]) config.Setting[Flags] {
+		if s.Set {
+			return s
+		}
+		var parsed Flags
+		if err := json.Unmarshal([]byte(value.String()), &parsed); err != nil {
+			return s
+		}
+		return s.Resolved(parsed, "ConvFlags", value)
+	}
+}
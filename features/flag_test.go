@@ -0,0 +1,125 @@
+package features
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jrrdcnnlly/core/config"
+	"github.com/jrrdcnnlly/core/logging"
+	"github.com/jrrdcnnlly/core/sessions"
+)
+
+// Create an authenticated session.
+func newUserSession(id string, userID string, username string) *sessions.Session {
+	session := sessions.NewSession(id)
+	session.UserID = userID
+	session.Username = username
+	return session
+}
+
+func TestConvFlags(t *testing.T) {
+	env := config.MapEnvironment{
+		"FEATURE_FLAGS": `{"new-ui": {"enabled": true, "percentage": 25, "userIds": ["u1"]}, "beta": true, "legacy": false}`,
+		"INVALID":       `{"new-ui": {"enabled": true, "percentage": 250}}`,
+	}
+
+	flags := config.Resolve(ConvFlags(config.EnvironmentVariable("FEATURE_FLAGS").In(env)))
+	expect := Flags{
+		"new-ui": {Enabled: true, Percentage: 25, UserIDs: []string{"u1"}},
+		"beta":   {Enabled: true, Percentage: 100},
+		"legacy": {Enabled: false},
+	}
+	if fmt.Sprint(flags.Value) != fmt.Sprint(expect) {
+		t.Errorf("ConvFlags() = %v; expect %v", flags.Value, expect)
+	}
+	if flags.Origin.String() != "map:FEATURE_FLAGS" {
+		t.Errorf("ConvFlags().Origin = %q; expect %q", flags.Origin, "map:FEATURE_FLAGS")
+	}
+
+	invalid := config.Resolve(ConvFlags(config.EnvironmentVariable("INVALID").In(env)), config.Fallback(Flags{}))
+	if len(invalid.Value) != 0 {
+		t.Errorf("ConvFlags() = %v; expect invalid flags to be rejected", invalid.Value)
+	}
+}
+
+func TestEvaluator_Enabled(t *testing.T) {
+	flags := Flags{
+		"beta":     {Enabled: true, Percentage: 100},
+		"disabled": {Enabled: false, UserIDs: []string{"u1"}, Percentage: 100},
+		"targeted": {Enabled: true, UserIDs: []string{"u1"}, Usernames: []string{"alice@example.com"}},
+	}
+	evaluator := NewEvaluator(func() Flags { return flags })
+	ctx := context.Background()
+
+	type Test struct {
+		name    string
+		flag    string
+		session *sessions.Session
+		expect  bool
+	}
+
+	tests := []Test{
+		{name: "Enabled() should be true for everyone", flag: "beta", session: sessions.NewSession("s1"), expect: true},
+		{name: "Enabled() should be true without a session", flag: "beta", session: nil, expect: true},
+		{name: "Enabled() should be false when disabled", flag: "disabled", session: newUserSession("s1", "u1", ""), expect: false},
+		{name: "Enabled() should be false for unknown flags", flag: "unknown", session: newUserSession("s1", "u1", ""), expect: false},
+		{name: "Enabled() should target user IDs", flag: "targeted", session: newUserSession("s1", "u1", ""), expect: true},
+		{name: "Enabled() should target usernames", flag: "targeted", session: newUserSession("s1", "u2", "alice@example.com"), expect: true},
+		{name: "Enabled() should be false for other users", flag: "targeted", session: newUserSession("s1", "u2", "bob@example.com"), expect: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := evaluator.Enabled(ctx, test.flag, test.session)
+			if result != test.expect {
+				t.Errorf("Evaluator.Enabled() = %v; expect %v", result, test.expect)
+			}
+		})
+	}
+}
+
+func TestEvaluator_Rollout(t *testing.T) {
+	flags := Flags{"rollout": {Enabled: true, Percentage: 25}}
+	evaluator := NewEvaluator(func() Flags { return flags })
+	ctx := context.Background()
+
+	total, enabled := 10000, 0
+	for i := range total {
+		session := newUserSession(fmt.Sprint("s", i), fmt.Sprint("u", i), "")
+		first := evaluator.Enabled(ctx, "rollout", session)
+		// Evaluations are deterministic.
+		if evaluator.Enabled(ctx, "rollout", session) != first {
+			t.Fatalf("Evaluator.Enabled() is not deterministic for %q", session.UserID)
+		}
+		if first {
+			enabled++
+		}
+	}
+
+	percentage := float64(enabled) * 100 / float64(total)
+	if math.Abs(percentage-25) > 2 {
+		t.Errorf("Evaluator.Enabled() enabled %.2f%% of users; expect about 25%%", percentage)
+	}
+}
+
+func TestEvaluator_Logging(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	r := logging.Request(httptest.NewRequest(http.MethodGet, "/", nil), logger)
+
+	evaluator := NewEvaluator(func() Flags { return Flags{"beta": {Enabled: true, Percentage: 100}} })
+	evaluator.EnabledFromContext(r.Context(), "beta")
+
+	for _, expect := range []string{"level=DEBUG", "flag.name=beta", "flag.enabled=true"} {
+		if !strings.Contains(logs.String(), expect) {
+			t.Errorf("logs = %q; expect to contain %q", logs.String(), expect)
+		}
+	}
+}
@@ -13,7 +13,7 @@ import (
 // TextHandler configuration.
 type textHandlerConfig struct {
 	w     io.Writer
-	level slog.Level
+	level slog.Leveler
 }
 
 // TextHandler option.
@@ -21,14 +21,15 @@ type TextHandlerOption func(cfg *textHandlerConfig)
 
 // TextHandler implements slog.Handler.
 type TextHandler struct {
-	level  slog.Level
+	level  slog.Leveler
 	attrs  []slog.Attr
 	group  string
 	writer io.Writer
 }
 
 // Create a hander with the specified level.
-func WithLevel(level slog.Level) TextHandlerOption {
+// Pass a *slog.LevelVar to change the level while the handler is in use.
+func WithLevel(level slog.Leveler) TextHandlerOption {
 	return func(cfg *textHandlerConfig) {
 		cfg.level = level
 	}
@@ -62,7 +63,7 @@ func NewTextHandler(options ...TextHandlerOption) *TextHandler {
 
 // Check if the handler should handle the specified level.
 func (h *TextHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.level.Level()
 }
 
 // Create a new handler with the specified attributes.
@@ -160,6 +161,8 @@ func (h *TextHandler) formatGroup(name string, depth int) string {
 
 // Format log record attribute.
 func (h *TextHandler) formatAttr(attr slog.Attr, depth int) string {
+	// Resolve slog.LogValuer values, e.g. redacted secrets.
+	attr.Value = attr.Value.Resolve()
 	if attr.Value.Kind() == slog.KindGroup {
 		text := fmt.Sprintf(
 			"%s\033[%dm%s:\033[0m\n",
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// Value that logs differently from how it prints, like config.Secret.
+type logValuer string
+
+func (v logValuer) String() string {
+	return string(v)
+}
+
+func (v logValuer) LogValue() slog.Value {
+	return slog.StringValue("[REDACTED]")
+}
+
+func TestTextHandler_LogValuer(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(NewTextHandler(WithWriter(&buffer)))
+	logger.With(slog.Any("attr", logValuer("hunter2"))).Info(
+		"test",
+		slog.Any("record", logValuer("hunter2")),
+		slog.Group("group", slog.Any("nested", logValuer("hunter2"))),
+	)
+
+	result := buffer.String()
+	if strings.Contains(result, "hunter2") {
+		t.Errorf("got %q; expect slog.LogValuer values to be resolved", result)
+	}
+	if count := strings.Count(result, "[REDACTED]"); count != 3 {
+		t.Errorf("got %d resolved values; expect 3", count)
+	}
+}
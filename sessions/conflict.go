@@ -0,0 +1,26 @@
+package sessions
+
+// Number of times the session middleware retries an update that conflicts.
+const conflictRetries int = 3
+
+// Resolve a conflicting session update.
+// Given the session as last saved by another request, and the session this request
+// attempted to save, return the session to save instead, or nil to discard this request's changes.
+type ConflictResolver func(stored *Session, attempted *Session) *Session
+
+// Resolve conflicts by applying the data keys this request set or deleted
+// on top of the stored session. The user fields are taken from this request
+// only if it changed them, e.g. by logging in.
+func MergeOnConflict(stored *Session, attempted *Session) *Session {
+	stored.mergeChanges(attempted)
+	if attempted.userChanged() {
+		stored.UserID = attempted.UserID
+		stored.Username = attempted.Username
+	}
+	return stored
+}
+
+// Resolve conflicts by saving this request's session again, replacing the changes of other requests.
+func OverwriteOnConflict(stored *Session, attempted *Session) *Session {
+	return attempted
+}
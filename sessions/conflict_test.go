@@ -0,0 +1,40 @@
+package sessions
+
+import "testing"
+
+func TestMergeOnConflict(t *testing.T) {
+	type Test struct {
+		name   string
+		login  bool   // Whether the attempted request logs in as u2.
+		expect string // Expected UserID after merging.
+	}
+
+	tests := []Test{
+		{"User fields changed by the request should be kept", true, "u2"},
+		{"Unchanged user fields should not reset a concurrent login", false, "u3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempted := NewSession("id")
+			attempted.Set("lang", "en")
+			if test.login {
+				attempted.UserID = "u2"
+				attempted.Username = "bob@example.com"
+			}
+			// Another request logged in as u3 and saved first.
+			stored := NewSession("id")
+			stored.UserID = "u3"
+			stored.Username = "carol@example.com"
+			stored.ClearChanged()
+
+			merged := MergeOnConflict(stored, attempted)
+			if merged.UserID != test.expect {
+				t.Errorf("MergeOnConflict().UserID = %q; expect %q", merged.UserID, test.expect)
+			}
+			if lang, _ := Get[string](merged, "lang"); lang != "en" {
+				t.Errorf("Get[string]() = %q; expect the data changes to be merged", lang)
+			}
+		})
+	}
+}
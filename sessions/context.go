@@ -3,6 +3,7 @@ package sessions
 import (
 	"context"
 	"errors"
+	"net/http"
 )
 
 // Used as a unique context key.
@@ -20,3 +21,13 @@ func FromContext(ctx context.Context) (*Session, error) {
 	}
 	return session, nil
 }
+
+// Create a new context that contains the specified session.
+func NewContext(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// Create a new request with a new context that contains the specified session.
+func Request(r *http.Request, session *Session) *http.Request {
+	return r.WithContext(NewContext(r.Context(), session))
+}
@@ -0,0 +1,175 @@
+package sessions
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maximum size of a session cookie value, leaving room for the name and attributes
+// within the 4096 byte limit browsers place on each cookie.
+const cookieChunkSize int = 3800
+
+// Maximum number of cookies a session cookie value may be split across.
+const maxCookieChunks int = 5
+
+// Session cookie configuration.
+type cookieConfig struct {
+	name     string
+	path     string
+	domain   string
+	secure   bool
+	httpOnly bool
+	sameSite http.SameSite
+	maxAge   time.Duration
+}
+
+// Split a session cookie value across as many cookies as needed, named "name", "name.1", "name.2"...
+func (cfg *cookieConfig) cookies(value string) []*http.Cookie {
+	var cookies []*http.Cookie
+	for i := 0; i == 0 || value != ""; i++ {
+		chunk := value[:min(len(value), cookieChunkSize)]
+		value = value[len(chunk):]
+		cookies = append(cookies, &http.Cookie{
+			Name:     chunkName(cfg.name, i),
+			Value:    chunk,
+			Path:     cfg.path,
+			Domain:   cfg.domain,
+			Secure:   cfg.secure,
+			HttpOnly: cfg.httpOnly,
+			SameSite: cfg.sameSite,
+			MaxAge:   int(cfg.maxAge / time.Second),
+		})
+	}
+	return cookies
+}
+
+// Create a cookie instructing the browser to delete a session cookie chunk.
+func (cfg *cookieConfig) clearCookie(name string) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     cfg.path,
+		Domain:   cfg.domain,
+		Secure:   cfg.secure,
+		HttpOnly: cfg.httpOnly,
+		SameSite: cfg.sameSite,
+		MaxAge:   -1,
+	}
+}
+
+// Return the name of the ith chunk of a session cookie.
+func chunkName(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+	return name + "." + strconv.Itoa(i)
+}
+
+// Read a session cookie value from a request, joining its chunks.
+// Returns the value and the number of chunks, 0 if there is no session cookie.
+func readCookie(r *http.Request, name string) (string, int) {
+	var value strings.Builder
+	chunks := 0
+	for ; chunks < maxCookieChunks; chunks++ {
+		cookie, err := r.Cookie(chunkName(name, chunks))
+		if err != nil {
+			break
+		}
+		value.WriteString(cookie.Value)
+	}
+	return value.String(), chunks
+}
+
+// Wraps http.ResponseWriter to write the session cookie before the response headers.
+type cookieWriter struct {
+	http.ResponseWriter
+	cfg     *cookieConfig
+	store   SessionStore
+	session *Session
+	chunks  int // Number of session cookie chunks sent with the request.
+	logger  *slog.Logger
+	written bool
+	err     error // Why the session cookie could not be written, if it failed.
+}
+
+// Create a new cookieWriter.
+func newCookieWriter(w http.ResponseWriter, cfg *cookieConfig, store SessionStore, session *Session, chunks int, logger *slog.Logger) *cookieWriter {
+	return &cookieWriter{
+		ResponseWriter: w,
+		cfg:            cfg,
+		store:          store,
+		session:        session,
+		chunks:         chunks,
+		logger:         logger,
+	}
+}
+
+// Add the session cookie to the response headers, once.
+// Destroyed sessions have their cookie cleared, as do chunks no longer needed.
+// If the session cannot be encoded, the response fails with a 500 rather than
+// leaving the client with a stale session cookie, and the error is returned.
+func (w *cookieWriter) writeCookie() error {
+	if w.written {
+		return w.err
+	}
+	w.written = true
+
+	// Move regenerated sessions to their new ID, or give them up if that fails.
+	if w.session.regenerate && !w.session.Destroyed() {
+		w.session.regenerate = false
+		err := w.store.Regenerate(w.session)
+		if err != nil {
+			w.logger.Error(
+				"failed to regenerate session",
+				slog.Any("err", err),
+			)
+			w.session.Destroy()
+		}
+	}
+
+	cookies := []*http.Cookie{}
+	if !w.session.Destroyed() {
+		value, err := cookieValue(w.store, w.session)
+		if err != nil {
+			w.logger.Error(
+				"failed to encode session cookie",
+				slog.Any("err", err),
+			)
+			w.err = err
+			w.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+			return err
+		}
+		cookies = w.cfg.cookies(value)
+	}
+	for _, cookie := range cookies {
+		http.SetCookie(w.ResponseWriter, cookie)
+	}
+	for i := len(cookies); i < max(w.chunks, 1); i++ {
+		http.SetCookie(w.ResponseWriter, w.cfg.clearCookie(chunkName(w.cfg.name, i)))
+	}
+	return nil
+}
+
+// Write the response status code, unless the session cookie failed.
+func (w *cookieWriter) WriteHeader(code int) {
+	if err := w.writeCookie(); err != nil {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write the response body, unless the session cookie failed.
+func (w *cookieWriter) Write(data []byte) (int, error) {
+	if err := w.writeCookie(); err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Return the wrapped http.ResponseWriter, for use by http.ResponseController.
+func (w *cookieWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,111 @@
+package sessions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jrrdcnnlly/core/config"
+	"github.com/jrrdcnnlly/core/id"
+)
+
+// Returned when an encoded session does not fit in the session cookie.
+var ErrCookieTooLarge = errors.New("session cookie too large")
+
+// Stateless session store keeping each session in an encrypted, authenticated session cookie.
+// Sessions are encrypted with the primary key of a keyring, older keys remain able to decrypt
+// sessions so keys can be rotated.
+// The cookie is written with the response headers, so changes made to the session
+// after the response has started are lost. Responses fail with a 500 if the session
+// is too large for its cookie, see ErrCookieTooLarge. Deleting a session only clears its cookie,
+// a copy kept by the client stays valid until it expires.
+// Create with NewCookieStore().
+type CookieStore struct {
+	id      *id.RandomGenerator
+	cfg     *storeConfig
+	keyring *config.Keyring
+}
+
+// Create a new CookieStore encrypting sessions with keyring.
+func NewCookieStore(keyring *config.Keyring, options ...StoreOption) *CookieStore {
+	return &CookieStore{
+		id:      id.NewRandomGenerator(),
+		cfg:     newStoreConfig(options...),
+		keyring: keyring,
+	}
+}
+
+// Create a new session.
+func (s *CookieStore) Create() (*Session, error) {
+	session := NewSession(s.id.Next())
+	s.cfg.start(session, time.Now())
+	return session, nil
+}
+
+// Retrieve a session by ID. Always fails, sessions are only kept in their cookie,
+// see Decode().
+func (s *CookieStore) Read(id string) (*Session, error) {
+	return nil, fmt.Errorf("no session with id %q, sessions are only kept in their cookie", id)
+}
+
+// Implement the CookieEncoder interface.
+// Decrypt a session from a session cookie value.
+func (s *CookieStore) Decode(value string) (*Session, error) {
+	// Refuse plaintext values, which the keyring would return unchanged.
+	if !strings.HasPrefix(value, config.EncryptedPrefix) {
+		return nil, fmt.Errorf("CookieStore.Decode(): %w", config.ErrInvalidCiphertext)
+	}
+	data, err := s.keyring.Decrypt(value)
+	if err != nil {
+		return nil, fmt.Errorf("CookieStore.Decode(): %w", err)
+	}
+	session := &Session{}
+	if err := json.Unmarshal([]byte(data), session); err != nil {
+		return nil, fmt.Errorf("CookieStore.Decode(): %w", err)
+	}
+
+	if session.Expired() {
+		return nil, fmt.Errorf("session %q has expired", session.id)
+	}
+
+	s.cfg.touch(session, time.Now())
+	return session, nil
+}
+
+// Implement the CookieEncoder interface.
+// Encrypt a session into a session cookie value.
+func (s *CookieStore) Encode(session *Session) (string, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", fmt.Errorf("CookieStore.Encode(): %w", err)
+	}
+	value, err := s.keyring.Encrypt(string(data))
+	if err != nil {
+		return "", fmt.Errorf("CookieStore.Encode(): %w", err)
+	}
+	if len(value) > cookieChunkSize*maxCookieChunks {
+		return "", fmt.Errorf("CookieStore.Encode(): %w: %d bytes", ErrCookieTooLarge, len(value))
+	}
+	return value, nil
+}
+
+// Update a session. Sessions are saved when their cookie is written.
+// Sessions carried by cookies never conflict, the last response to set the cookie wins.
+func (s *CookieStore) Update(session *Session) error {
+	session.ClearChanged()
+	return nil
+}
+
+// Delete a session. Sessions are deleted when their cookie is cleared.
+func (s *CookieStore) Delete(id string) error {
+	return nil
+}
+
+// Move a session to a new ID.
+// The previous cookie value is replaced when the session cookie is written.
+func (s *CookieStore) Regenerate(session *Session) error {
+	session.id = s.id.Next()
+	return nil
+}
@@ -0,0 +1,221 @@
+package sessions
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrrdcnnlly/core/config"
+)
+
+// Create a keyring holding a single random key.
+func newTestKeyring(t *testing.T, id string) *config.Keyring {
+	key, err := config.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := config.NewKeyring()
+	if err := keyring.Add(id, key); err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+// Return the cookies set by a response, by name.
+func responseCookies(res *http.Response) map[string]*http.Cookie {
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range res.Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+func TestCookieStore(t *testing.T) {
+	store := NewCookieStore(newTestKeyring(t, "k1"))
+	handler := Middleware(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := FromContext(r.Context())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		visits, _ := Get[int](session, "visits")
+		session.Set("visits", visits+1)
+	}))
+
+	first := responseCookie(t, serve(handler), sessionCookie)
+	if !strings.HasPrefix(first.Value, config.EncryptedPrefix) {
+		t.Fatalf("cookie value = %q; expect an encrypted session", first.Value)
+	}
+	second := responseCookie(t, serve(handler, first), sessionCookie)
+	session, err := store.Decode(second.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visits, _ := Get[int](session, "visits"); visits != 2 {
+		t.Errorf("Get[int]() = %d; expect 2 visits carried by the cookie", visits)
+	}
+
+	// Tampered and forged cookies are refused.
+	tampered := *second
+	tampered.Value = second.Value[:len(second.Value)-4] + "AAAA"
+	if _, err := store.Decode(tampered.Value); !errors.Is(err, config.ErrInvalidCiphertext) {
+		t.Errorf("CookieStore.Decode() error = %v; expect %v", err, config.ErrInvalidCiphertext)
+	}
+	if _, err := store.Decode(`{"id":"forged"}`); !errors.Is(err, config.ErrInvalidCiphertext) {
+		t.Errorf("CookieStore.Decode() error = %v; expect plaintext to be refused", err)
+	}
+}
+
+func TestCookieStore_KeyRotation(t *testing.T) {
+	keyring := newTestKeyring(t, "old")
+	store := NewCookieStore(keyring)
+	session, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := store.Encode(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := config.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.Add("new", key); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.SetPrimary("new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Decode(value); err != nil {
+		t.Errorf("CookieStore.Decode() error = %v; expect sessions encrypted with old keys to be readable", err)
+	}
+	rotated, err := store.Encode(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rotated, config.EncryptedPrefix+"new:") {
+		t.Errorf("CookieStore.Encode() = %q; expect the new primary key", rotated)
+	}
+}
+
+func TestCookieStore_Expiry(t *testing.T) {
+	store := NewCookieStore(newTestKeyring(t, "k1"))
+	session, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.Expires = time.Now().Add(-time.Minute)
+	value, err := store.Encode(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Decode(value); err == nil {
+		t.Errorf("CookieStore.Decode() error = nil; expect an expired session to be refused")
+	}
+}
+
+func TestCookieStore_Chunks(t *testing.T) {
+	store := NewCookieStore(newTestKeyring(t, "k1"))
+	var size int
+	handler := Middleware(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := FromContext(r.Context())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		session.Set("pad", strings.Repeat("x", size))
+	}))
+
+	type Test struct {
+		size   int
+		expect int // Number of chunks set.
+	}
+
+	tests := []Test{
+		{size: 10000, expect: 4},
+		{size: 5000, expect: 2},
+		{size: 10, expect: 1},
+	}
+
+	var cookies []*http.Cookie
+	for _, test := range tests {
+		size = test.size
+		res := serve(handler, cookies...)
+		set := responseCookies(res)
+
+		cookies = nil
+		for i := range maxCookieChunks {
+			cookie, ok := set[chunkName(sessionCookie, i)]
+			if !ok {
+				break
+			}
+			if cookie.MaxAge < 0 {
+				continue
+			}
+			cookies = append(cookies, cookie)
+		}
+		if len(cookies) != test.expect {
+			t.Errorf("%d bytes of data set %d cookies; expect %d", test.size, len(cookies), test.expect)
+		}
+		// Chunks left over from a larger session are cleared.
+		if cleared := len(set) - len(cookies); test.size < 10000 && cleared == 0 {
+			t.Errorf("%d bytes of data cleared no stale chunks", test.size)
+		}
+	}
+
+	// Sessions too large for the cookie fail the response and keep the previous cookie.
+	size = cookieChunkSize * maxCookieChunks
+	res := serve(handler, cookies...)
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d; expect %d for a session too large to encode", res.StatusCode, http.StatusInternalServerError)
+	}
+	if set := res.Cookies(); len(set) != 0 {
+		t.Errorf("response set %d cookies; expect none for a session too large to encode", len(set))
+	}
+}
+
+func TestCookieStore_EncodeFailure(t *testing.T) {
+	store := NewCookieStore(newTestKeyring(t, "k1"))
+	handler := Middleware(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := FromContext(r.Context())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		session.Set("pad", strings.Repeat("x", cookieChunkSize*maxCookieChunks))
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte("ok")); err == nil {
+			t.Errorf("Write() error = nil; expect the failed session cookie")
+		}
+	}))
+
+	res := serve(handler)
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d; expect %d", res.StatusCode, http.StatusInternalServerError)
+	}
+	if body, _ := io.ReadAll(res.Body); len(body) != 0 {
+		t.Errorf("body = %q; expect the handler's response to be discarded", body)
+	}
+}
+
+func TestCookieStore_Read(t *testing.T) {
+	store := NewCookieStore(newTestKeyring(t, "k1"))
+	session, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := store.Encode(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{session.ID(), value} {
+		if _, err := store.Read(id); err == nil {
+			t.Errorf("CookieStore.Read() error = nil; expect sessions to be decoded, not read by ID")
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package sessions
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Store a value in the session data under key.
+// Values must be serializable as JSON to be kept by persistent stores.
+// Stores copy values through JSON even in memory, so unexported struct fields are not kept.
+// Modified values must be set again for the change to be tracked.
+func (s *Session) Set(key string, value any) {
+	if s.data == nil {
+		s.data = map[string]any{}
+	}
+	s.data[key] = value
+	s.markChanged(key)
+}
+
+// Remove the value stored under key from the session data.
+func (s *Session) Delete(key string) {
+	if _, ok := s.data[key]; !ok {
+		return
+	}
+	delete(s.data, key)
+	s.markChanged(key)
+}
+
+// Return the keys of the session data in sorted order.
+func (s *Session) Keys() []string {
+	return slices.Sorted(maps.Keys(s.data))
+}
+
+// Has the session data, UserID or Username changed since the session was created or loaded?
+func (s *Session) Changed() bool {
+	return len(s.changed) > 0 || s.userChanged()
+}
+
+// Mark the session as unchanged, e.g. after a store has saved it.
+func (s *Session) ClearChanged() {
+	s.changed = nil
+	s.loaded = account{s.UserID, s.Username}
+}
+
+// Have UserID or Username changed since the session was created or loaded?
+func (s *Session) userChanged() bool {
+	return s.loaded != account{s.UserID, s.Username}
+}
+
+// Record that the value of key has been set or deleted.
+func (s *Session) markChanged(key string) {
+	if s.changed == nil {
+		s.changed = map[string]struct{}{}
+	}
+	s.changed[key] = struct{}{}
+}
+
+// Apply the data changes made to other on top of the session.
+func (s *Session) mergeChanges(other *Session) {
+	for key := range other.changed {
+		if value, ok := other.data[key]; ok {
+			s.Set(key, value)
+		} else {
+			s.Delete(key)
+		}
+	}
+}
+
+// Retrieve a typed value from the session data.
+// Values read from a store are decoded from JSON into T, so an interface T such as any
+// receives the JSON form of the value, e.g. map[string]any for a struct.
+// If the key is not set, or its value is not a T, false is returned as the second value.
+func Get[T any](s *Session, key string) (T, bool) {
+	var zero T
+	value, ok := s.data[key]
+	if !ok {
+		return zero, false
+	}
+	// Values loaded by a persistent store are decoded on every use,
+	// so reading never modifies the session and each T decodes the same JSON.
+	raw, isRaw := value.(json.RawMessage)
+	if typed, ok := value.(T); ok && (!isRaw || reflect.TypeFor[T]() == reflect.TypeFor[json.RawMessage]()) {
+		return typed, true
+	}
+	if !isRaw {
+		return zero, false
+	}
+	var typed T
+	if err := json.Unmarshal(raw, &typed); err != nil {
+		return zero, false
+	}
+	return typed, true
+}
+
+// Serialized form of a session.
+type sessionJSON struct {
+	ID       string                     `json:"id"`
+	Version  int64                      `json:"version"`
+	Created  time.Time                  `json:"created"`
+	Accessed time.Time                  `json:"accessed"`
+	Expires  time.Time                  `json:"expires"`
+	UserID   string                     `json:"userId,omitempty"`
+	Username string                     `json:"username,omitempty"`
+	Data     map[string]json.RawMessage `json:"data,omitempty"`
+}
+
+// Implement the json.Marshaler interface, for use by persistent stores.
+func (s *Session) MarshalJSON() ([]byte, error) {
+	data := make(map[string]json.RawMessage, len(s.data))
+	for key, value := range s.data {
+		if raw, ok := value.(json.RawMessage); ok {
+			data[key] = raw
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		data[key] = raw
+	}
+	return json.Marshal(sessionJSON{
+		ID:       s.id,
+		Version:  s.version,
+		Created:  s.Created,
+		Accessed: s.Accessed,
+		Expires:  s.Expires,
+		UserID:   s.UserID,
+		Username: s.Username,
+		Data:     data,
+	})
+}
+
+// Implement the json.Unmarshaler interface, for use by persistent stores.
+// Data values are decoded when retrieved with Get().
+func (s *Session) UnmarshalJSON(b []byte) error {
+	var parsed sessionJSON
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		return err
+	}
+	data := make(map[string]any, len(parsed.Data))
+	for key, raw := range parsed.Data {
+		data[key] = raw
+	}
+	*s = Session{
+		id:       parsed.ID,
+		version:  parsed.Version,
+		Created:  parsed.Created,
+		Accessed: parsed.Accessed,
+		Expires:  parsed.Expires,
+		UserID:   parsed.UserID,
+		Username: parsed.Username,
+		data:     data,
+		loaded:   account{parsed.UserID, parsed.Username},
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package sessions
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Example structured session value.
+type cart struct {
+	Items []string `json:"items"`
+	Total float64  `json:"total"`
+}
+
+func TestSession_Data(t *testing.T) {
+	session := NewSession("id")
+	if session.Changed() {
+		t.Errorf("Session.Changed() = true; expect false for a new session")
+	}
+
+	session.Set("theme", "dark")
+	session.Set("cart", cart{Items: []string{"apple"}, Total: 1.5})
+	if !session.Changed() {
+		t.Errorf("Session.Changed() = false; expect true after Set()")
+	}
+
+	if theme, ok := Get[string](session, "theme"); !ok || theme != "dark" {
+		t.Errorf("Get[string]() = %q, %v; expect %q, true", theme, ok, "dark")
+	}
+	if _, ok := Get[int](session, "theme"); ok {
+		t.Errorf("Get[int]() ok = true; expect false for a string value")
+	}
+	if _, ok := Get[string](session, "missing"); ok {
+		t.Errorf("Get[string]() ok = true; expect false for a missing key")
+	}
+	if keys := session.Keys(); !slices.Equal(keys, []string{"cart", "theme"}) {
+		t.Errorf("Session.Keys() = %v; expect [cart theme]", keys)
+	}
+
+	session.ClearChanged()
+	session.Delete("missing")
+	if session.Changed() {
+		t.Errorf("Session.Changed() = true; expect false after deleting a missing key")
+	}
+	session.Delete("theme")
+	if !session.Changed() {
+		t.Errorf("Session.Changed() = false; expect true after Delete()")
+	}
+
+	session.ClearChanged()
+	session.UserID = "u1"
+	if !session.Changed() {
+		t.Errorf("Session.Changed() = false; expect true after setting UserID")
+	}
+	session.ClearChanged()
+	session.UserID = "u1"
+	if session.Changed() {
+		t.Errorf("Session.Changed() = true; expect false after setting UserID to its current value")
+	}
+}
+
+func TestGet_Interface(t *testing.T) {
+	session := NewSession("id")
+	session.Set("theme", "dark")
+	session.Set("cart", cart{Items: []string{"apple"}, Total: 1.5})
+
+	// Values are copied through JSON, as a persistent store would load them.
+	clone := session.clone()
+
+	type Test struct {
+		name   string
+		key    string
+		expect any
+	}
+
+	tests := []Test{
+		{name: "Get[any]() should decode a string", key: "theme", expect: "dark"},
+		{name: "Get[any]() should decode a struct as a map", key: "cart", expect: map[string]any{"items": []any{"apple"}, "total": 1.5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, ok := Get[any](clone, test.key)
+			if !ok || !reflect.DeepEqual(result, test.expect) {
+				t.Errorf("Get[any]() = %#v, %v; expect %#v, true", result, ok, test.expect)
+			}
+		})
+	}
+
+	if raw, ok := Get[json.RawMessage](clone, "theme"); !ok || string(raw) != `"dark"` {
+		t.Errorf("Get[json.RawMessage]() = %s, %v; expect %s, true", raw, ok, `"dark"`)
+	}
+}
+
+func TestSession_JSON(t *testing.T) {
+	expires := time.Now().Add(time.Hour).Round(0).UTC()
+	session := NewSession("id")
+	session.Expires = expires
+	session.UserID = "u1"
+	session.Username = "alice@example.com"
+	session.Set("visits", 3)
+	session.Set("cart", cart{Items: []string{"apple", "pear"}, Total: 2.5})
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Session
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.ID() != "id" || !loaded.Expires.Equal(expires) || loaded.UserID != "u1" || loaded.Username != "alice@example.com" {
+		t.Errorf("loaded session = %+v; expect fields to round trip", loaded)
+	}
+	if loaded.Changed() {
+		t.Errorf("Session.Changed() = true; expect false for a loaded session")
+	}
+	if visits, ok := Get[int](&loaded, "visits"); !ok || visits != 3 {
+		t.Errorf("Get[int]() = %d, %v; expect 3, true", visits, ok)
+	}
+	// Retrieving a loaded value does not fix its type.
+	if visits, ok := Get[float64](&loaded, "visits"); !ok || visits != 3 {
+		t.Errorf("Get[float64]() = %v, %v; expect 3, true", visits, ok)
+	}
+	c, ok := Get[cart](&loaded, "cart")
+	if !ok || !slices.Equal(c.Items, []string{"apple", "pear"}) || c.Total != 2.5 {
+		t.Errorf("Get[cart]() = %+v, %v; expect the cart to round trip", c, ok)
+	}
+	if _, ok := loaded.data["cart"].(json.RawMessage); !ok {
+		t.Errorf("Get[cart]() modified the session data")
+	}
+
+	// Values that have not been retrieved survive another round trip.
+	again, err := json.Marshal(&loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reloaded Session
+	if err := json.Unmarshal(again, &reloaded); err != nil {
+		t.Fatal(err)
+	}
+	if visits, ok := Get[int](&reloaded, "visits"); !ok || visits != 3 {
+		t.Errorf("Get[int]() = %d, %v; expect 3, true", visits, ok)
+	}
+}
@@ -0,0 +1,83 @@
+package sessions
+
+import "time"
+
+// Default session lifetime.
+const defaultLifetime time.Duration = 24 * time.Hour
+
+// Session store configuration.
+type storeConfig struct {
+	lifetime        time.Duration
+	idleTimeout     time.Duration
+	absoluteTimeout time.Duration
+	redis           *redisStoreConfig // RedisStore options, nil if none are set.
+}
+
+// Session store option.
+type StoreOption func(cfg *storeConfig)
+
+// Optional lifetime of sessions without an idle timeout. Defaults to 24 hours.
+func WithLifetime(lifetime time.Duration) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.lifetime = lifetime
+	}
+}
+
+// Optional idle timeout. Sessions expire after this long without being read,
+// every read slides the expiry forward. Replaces the lifetime when set.
+// Defaults to 0, no idle timeout.
+func WithIdleTimeout(timeout time.Duration) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.idleTimeout = timeout
+	}
+}
+
+// Optional absolute timeout. Sessions expire this long after being created,
+// regardless of activity. Defaults to 0, no absolute timeout.
+func WithAbsoluteTimeout(timeout time.Duration) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.absoluteTimeout = timeout
+	}
+}
+
+// Create a store configuration from options.
+func newStoreConfig(options ...StoreOption) *storeConfig {
+	// Init default config.
+	cfg := &storeConfig{
+		lifetime: defaultLifetime,
+	}
+	// Apply options to config.
+	for _, option := range options {
+		option(cfg)
+	}
+	return cfg
+}
+
+// Initialize the timestamps of a new session.
+func (cfg *storeConfig) start(session *Session, now time.Time) {
+	session.Created = now
+	session.Accessed = now
+	session.Expires = cfg.expires(session)
+}
+
+// Record activity on a session, sliding its expiry when there is an idle timeout.
+func (cfg *storeConfig) touch(session *Session, now time.Time) {
+	session.Accessed = now
+	if cfg.idleTimeout > 0 {
+		session.Expires = cfg.expires(session)
+	}
+}
+
+// Calculate when a session expires.
+func (cfg *storeConfig) expires(session *Session) time.Time {
+	expires := session.Created.Add(cfg.lifetime)
+	if cfg.idleTimeout > 0 {
+		expires = session.Accessed.Add(cfg.idleTimeout)
+	}
+	if cfg.absoluteTimeout > 0 {
+		if limit := session.Created.Add(cfg.absoluteTimeout); limit.Before(expires) {
+			expires = limit
+		}
+	}
+	return expires
+}
@@ -0,0 +1,62 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStoreConfig_Expires(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type Test struct {
+		name     string
+		options  []StoreOption
+		accessed time.Duration // Time of the last access after creation.
+		expect   time.Duration // Expiry after creation.
+	}
+
+	tests := []Test{
+		{name: "Sessions should expire after the default lifetime", expect: 24 * time.Hour},
+		{name: "Sessions should expire after the lifetime", options: []StoreOption{WithLifetime(time.Hour)}, accessed: 30 * time.Minute, expect: time.Hour},
+		{name: "Sessions should expire after the idle timeout", options: []StoreOption{WithIdleTimeout(15 * time.Minute)}, expect: 15 * time.Minute},
+		{name: "Idle timeouts should slide on activity", options: []StoreOption{WithIdleTimeout(15 * time.Minute)}, accessed: time.Hour, expect: time.Hour + 15*time.Minute},
+		{name: "Absolute timeouts should cap the lifetime", options: []StoreOption{WithAbsoluteTimeout(time.Hour)}, expect: time.Hour},
+		{name: "Absolute timeouts should cap idle timeouts", options: []StoreOption{WithIdleTimeout(15 * time.Minute), WithAbsoluteTimeout(time.Hour)}, accessed: 50 * time.Minute, expect: time.Hour},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := newStoreConfig(test.options...)
+			session := NewSession("id")
+			cfg.start(session, created)
+			cfg.touch(session, created.Add(test.accessed))
+
+			expect := created.Add(test.expect)
+			if !session.Expires.Equal(expect) {
+				t.Errorf("Session.Expires = %v; expect %v", session.Expires, expect)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_IdleTimeout(t *testing.T) {
+	store := NewMemoryStore(WithIdleTimeout(time.Hour))
+
+	session, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Expired() {
+		t.Fatalf("Session.Expired() = true; expect a new session to be valid")
+	}
+
+	// Simulate two idle hours, past the one hour idle timeout.
+	session.Accessed = session.Accessed.Add(-2 * time.Hour)
+	session.Expires = session.Expires.Add(-2 * time.Hour)
+	if err := store.Update(session); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Read(session.ID()); err == nil {
+		t.Errorf("MemoryStore.Read() error = nil; expect an idle session to have expired")
+	}
+}
@@ -0,0 +1,278 @@
+package sessions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jrrdcnnlly/core/id"
+)
+
+// Name of the file locked while a FileStore directory is modified.
+const lockFile string = ".lock"
+
+// Extension of session files.
+const sessionExt string = ".json"
+
+// Session store persisting each session as a JSON file in a directory,
+// so sessions survive restarts. Processes on the same host may share a directory.
+// Create with NewFileStore().
+type FileStore struct {
+	id     *id.RandomGenerator
+	cfg    *storeConfig
+	dir    string
+	lock   *os.File   // Locked to sync access between processes.
+	mutex  sync.Mutex // Sync access between goroutines.
+	ticker *time.Ticker
+	done   chan struct{} // Closed to stop the cleanup goroutine.
+}
+
+// Create a new FileStore in dir, creating the directory if needed.
+func NewFileStore(dir string, options ...StoreOption) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("NewFileStore: %w", err)
+	}
+	lock, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("NewFileStore: %w", err)
+	}
+
+	store := &FileStore{
+		id:     id.NewRandomGenerator(),
+		cfg:    newStoreConfig(options...),
+		dir:    dir,
+		lock:   lock,
+		ticker: time.NewTicker(time.Hour),
+		done:   make(chan struct{}),
+	}
+
+	// Every hour run cleanup to remove expired sessions, until the store is closed.
+	go func() {
+		for {
+			select {
+			case <-store.ticker.C:
+				store.Cleanup()
+			case <-store.done:
+				return
+			}
+		}
+	}()
+
+	return store, nil
+}
+
+// Create a new session in the store.
+func (s *FileStore) Create() (*Session, error) {
+	unlock, err := s.lockExclusive()
+	if err != nil {
+		return nil, fmt.Errorf("FileStore.Create(): %w", err)
+	}
+	defer unlock()
+
+	session := NewSession(s.id.Next())
+	s.cfg.start(session, time.Now())
+	if err := s.write(session); err != nil {
+		return nil, fmt.Errorf("FileStore.Create(): %w", err)
+	}
+	return session, nil
+}
+
+// Retrieve a session from the store.
+func (s *FileStore) Read(id string) (*Session, error) {
+	unlock, err := s.lockExclusive()
+	if err != nil {
+		return nil, fmt.Errorf("FileStore.Read(): %w", err)
+	}
+	defer unlock()
+
+	session, err := s.read(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no session with id %q", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("FileStore.Read(): %w", err)
+	}
+
+	if session.Expired() {
+		return nil, fmt.Errorf("session %q has expired", id)
+	}
+
+	// Save the access time when idle timeouts slide, otherwise reads leave the file alone.
+	s.cfg.touch(session, time.Now())
+	if s.cfg.idleTimeout > 0 {
+		if err := s.write(session); err != nil {
+			return nil, fmt.Errorf("FileStore.Read(): %w", err)
+		}
+	}
+	return session, nil
+}
+
+// Update a session in the store.
+// Sessions deleted or regenerated since they were read are not saved again.
+func (s *FileStore) Update(session *Session) error {
+	unlock, err := s.lockExclusive()
+	if err != nil {
+		return fmt.Errorf("FileStore.Update(): %w", err)
+	}
+	defer unlock()
+
+	stored, err := s.read(s.path(session.id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("FileStore.Update(): no session with id %q", session.id)
+	}
+	if err != nil {
+		return fmt.Errorf("FileStore.Update(): %w", err)
+	}
+	if stored.version != session.version {
+		return fmt.Errorf("FileStore.Update(): %w", ErrConflict)
+	}
+
+	session.version++
+	if err := s.write(session); err != nil {
+		session.version--
+		return fmt.Errorf("FileStore.Update(): %w", err)
+	}
+	session.ClearChanged()
+	return nil
+}
+
+// Delete a session from the store.
+func (s *FileStore) Delete(id string) error {
+	unlock, err := s.lockExclusive()
+	if err != nil {
+		return fmt.Errorf("FileStore.Delete(): %w", err)
+	}
+	defer unlock()
+
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("FileStore.Delete(): %w", err)
+	}
+	return nil
+}
+
+// Move a session to a new ID and delete the old ID.
+func (s *FileStore) Regenerate(session *Session) error {
+	unlock, err := s.lockExclusive()
+	if err != nil {
+		return fmt.Errorf("FileStore.Regenerate(): %w", err)
+	}
+	defer unlock()
+
+	old := s.path(session.id)
+	session.id = s.id.Next()
+	if err := s.write(session); err != nil {
+		return fmt.Errorf("FileStore.Regenerate(): %w", err)
+	}
+	if err := os.Remove(old); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("FileStore.Regenerate(): %w", err)
+	}
+	return nil
+}
+
+// Stop the cleanup goroutine and close the lock file.
+// The store must not be used after it is closed.
+func (s *FileStore) Close() error {
+	s.ticker.Stop()
+	close(s.done)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.lock.Close(); err != nil {
+		return fmt.Errorf("FileStore.Close(): %w", err)
+	}
+	return nil
+}
+
+// Delete expired and unreadable session files from the store.
+func (s *FileStore) Cleanup() {
+	unlock, err := s.lockExclusive()
+	if err != nil {
+		slog.Warn("failed to lock session directory", slog.String("dir", s.dir), slog.Any("err", err))
+		return
+	}
+	defer unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		slog.Warn("failed to read session directory", slog.String("dir", s.dir), slog.Any("err", err))
+		return
+	}
+	for _, entry := range entries {
+		path := filepath.Join(s.dir, entry.Name())
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), sessionExt) {
+			continue
+		}
+		session, err := s.read(path)
+		if err == nil && !session.Expired() {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			slog.Warn("failed to remove session file", slog.String("path", path), slog.Any("err", err))
+		}
+	}
+}
+
+// Return the path of the file a session is stored in.
+// IDs are hashed so they are safe to use as file names.
+func (s *FileStore) path(id string) string {
+	hash := sha256.Sum256([]byte(id))
+	return filepath.Join(s.dir, hex.EncodeToString(hash[:])+sessionExt)
+}
+
+// Read a session file.
+func (s *FileStore) read(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("invalid session file %q: %w", path, err)
+	}
+	return session, nil
+}
+
+// Atomically write a session file.
+func (s *FileStore) write(session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	path := s.path(session.id)
+	temp, err := os.CreateTemp(s.dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// Lock the store against other goroutines and processes.
+// Returns a function that releases the lock.
+func (s *FileStore) lockExclusive() (func(), error) {
+	s.mutex.Lock()
+	if err := lockFileExclusive(s.lock); err != nil {
+		s.mutex.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlockFile(s.lock) // Ignore returned error, the lock is released when the file is closed.
+		s.mutex.Unlock()
+	}, nil
+}
@@ -0,0 +1,164 @@
+package sessions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Create a FileStore in a temporary directory.
+func newTestFileStore(t *testing.T, dir string, options ...StoreOption) *FileStore {
+	store, err := NewFileStore(dir, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestFileStore(t *testing.T) {
+	testStore(t, func(t *testing.T, options ...StoreOption) SessionStore {
+		return newTestFileStore(t, t.TempDir(), options...)
+	})
+}
+
+func TestFileStore_Restart(t *testing.T) {
+	dir := t.TempDir()
+	session, err := newTestFileStore(t, dir).Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new store on the same directory simulates a restart.
+	if _, err := newTestFileStore(t, dir).Read(session.ID()); err != nil {
+		t.Errorf("FileStore.Read() error = %v; expect sessions to survive a restart", err)
+	}
+}
+
+func TestFileStore_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	stores := []*FileStore{newTestFileStore(t, dir), newTestFileStore(t, dir)}
+	session, err := stores[0].Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			store := stores[i%len(stores)]
+			read, err := store.Read(session.ID())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			// Updates racing with another update may conflict.
+			if err := store.Update(read); err != nil && !errors.Is(err, ErrConflict) {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	// Atomic writes should never leave a partial file behind.
+	if _, err := stores[1].Read(session.ID()); err != nil {
+		t.Errorf("FileStore.Read() error = %v; expect a valid session", err)
+	}
+}
+
+func TestFileStore_Cleanup(t *testing.T) {
+	dir := t.TempDir()
+	store := newTestFileStore(t, dir)
+
+	valid, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.Expires = time.Now().Add(-time.Minute)
+	if err := store.Update(expired); err != nil {
+		t.Fatal(err)
+	}
+	corrupt := filepath.Join(dir, "corrupt"+sessionExt)
+	if err := os.WriteFile(corrupt, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store.Cleanup()
+
+	type Test struct {
+		path   string
+		expect bool
+	}
+
+	tests := []Test{
+		{store.path(valid.ID()), true},
+		{store.path(expired.ID()), false},
+		{corrupt, false},
+		{filepath.Join(dir, lockFile), true},
+	}
+
+	for _, test := range tests {
+		_, err := os.Stat(test.path)
+		if actual := err == nil; actual != test.expect {
+			t.Errorf("file %s exists = %v; expect %v", filepath.Base(test.path), actual, test.expect)
+		}
+	}
+}
+
+func TestFileStore_ReadWithoutIdleTimeout(t *testing.T) {
+	type Test struct {
+		name    string
+		options []StoreOption
+		expect  bool
+	}
+
+	tests := []Test{
+		{"Reads should not rewrite the file without an idle timeout", nil, false},
+		{"Reads should save the access time with an idle timeout", []StoreOption{WithIdleTimeout(time.Hour)}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := newTestFileStore(t, t.TempDir(), test.options...)
+			session, err := store.Create()
+			if err != nil {
+				t.Fatal(err)
+			}
+			before, err := os.ReadFile(store.path(session.ID()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(time.Millisecond)
+			if _, err := store.Read(session.ID()); err != nil {
+				t.Fatal(err)
+			}
+			after, err := os.ReadFile(store.path(session.ID()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := string(after) != string(before); actual != test.expect {
+				t.Errorf("file rewritten = %v; expect %v", actual, test.expect)
+			}
+		})
+	}
+}
+
+func TestFileStore_Close(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("FileStore.Close() error = %v", err)
+	}
+	if _, err := store.Create(); err == nil {
+		t.Errorf("FileStore.Create() error = nil; expect an error after Close()")
+	}
+}
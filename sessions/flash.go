@@ -0,0 +1,57 @@
+package sessions
+
+import (
+	"slices"
+)
+
+// Session data key flash messages are stored under.
+const flashKey string = "_flashes"
+
+// Common flash message categories.
+const (
+	FlashSuccess string = "success"
+	FlashInfo    string = "info"
+	FlashWarning string = "warning"
+	FlashError   string = "error"
+)
+
+// One-time message shown to the user on their next page view, e.g. after a redirect.
+type Flash struct {
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
+
+// Add a flash message in category to the session.
+func (s *Session) AddFlash(category string, message string) {
+	flashes, _ := Get[[]Flash](s, flashKey)
+	// Copy the flashes, the slice may be shared with copies of the session.
+	s.Set(flashKey, append(slices.Clip(flashes), Flash{Category: category, Message: message}))
+}
+
+// Return and remove the flash messages in the given categories, in the order they were added.
+// All flash messages are returned if no categories are given.
+func (s *Session) Flashes(categories ...string) []Flash {
+	flashes, ok := Get[[]Flash](s, flashKey)
+	if !ok {
+		return nil
+	}
+
+	var read, kept []Flash
+	for _, flash := range flashes {
+		if len(categories) == 0 || slices.Contains(categories, flash.Category) {
+			read = append(read, flash)
+		} else {
+			kept = append(kept, flash)
+		}
+	}
+	if len(read) == 0 {
+		return nil
+	}
+
+	if len(kept) == 0 {
+		s.Delete(flashKey)
+	} else {
+		s.Set(flashKey, kept)
+	}
+	return read
+}
@@ -0,0 +1,56 @@
+package sessions
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestSession_Flashes(t *testing.T) {
+	session := NewSession("id")
+	session.AddFlash(FlashSuccess, "Signed in successfully")
+	session.AddFlash(FlashError, "Upload failed")
+	session.AddFlash(FlashSuccess, "Profile saved")
+
+	// Flashes must survive a round trip through a persistent store.
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &Session{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	type Test struct {
+		categories []string
+		expect     []Flash
+	}
+
+	tests := []Test{
+		{[]string{FlashWarning}, nil},
+		{[]string{FlashSuccess}, []Flash{
+			{FlashSuccess, "Signed in successfully"},
+			{FlashSuccess, "Profile saved"},
+		}},
+		{[]string{FlashSuccess}, nil},
+		{nil, []Flash{
+			{FlashError, "Upload failed"},
+		}},
+		{nil, nil},
+	}
+
+	for _, test := range tests {
+		actual := loaded.Flashes(test.categories...)
+		if !slices.Equal(actual, test.expect) {
+			t.Errorf("Session.Flashes(%v) = %v; expect %v", test.categories, actual, test.expect)
+		}
+	}
+
+	if keys := loaded.Keys(); len(keys) != 0 {
+		t.Errorf("Session.Keys() = %v; expect no keys once all flashes are read", keys)
+	}
+	if !loaded.Changed() {
+		t.Errorf("Session.Changed() = false; expect true after reading flashes")
+	}
+}
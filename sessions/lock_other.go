@@ -0,0 +1,16 @@
+//go:build !unix
+
+package sessions
+
+import "os"
+
+// File locking is not supported on this platform,
+// only goroutines within a single process are synchronized.
+func lockFileExclusive(file *os.File) error {
+	return nil
+}
+
+// Release an advisory lock on a file.
+func unlockFile(file *os.File) error {
+	return nil
+}
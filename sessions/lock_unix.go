@@ -0,0 +1,18 @@
+//go:build unix
+
+package sessions
+
+import (
+	"os"
+	"syscall"
+)
+
+// Take an exclusive advisory lock on a file, blocking until it is available.
+func lockFileExclusive(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+}
+
+// Release an advisory lock on a file.
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
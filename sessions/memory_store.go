@@ -2,7 +2,6 @@ package sessions
 
 import (
 	"fmt"
-	"net/http"
 	"sync"
 	"time"
 
@@ -10,17 +9,22 @@ import (
 )
 
 // Session store held entirely in memory.
+// Sessions are copied in and out of the store, so concurrent requests on one session
+// each work on their own copy and changes are only kept once passed to Update().
+// Data values are copied through JSON, like a persistent store, see Get().
 // Create with NewMemoryStore().
 type MemoryStore struct {
 	id       *id.RandomGenerator
+	cfg      *storeConfig
 	sessions map[string]*Session
 	mutex    sync.Mutex
 }
 
 // Create a new MemoryStore.
-func NewMemoryStore() *MemoryStore {
+func NewMemoryStore(options ...StoreOption) *MemoryStore {
 	store := &MemoryStore{
 		id:       id.NewRandomGenerator(),
+		cfg:      newStoreConfig(options...),
 		sessions: map[string]*Session{},
 	}
 
@@ -41,8 +45,9 @@ func (s *MemoryStore) Create() (*Session, error) {
 	defer s.mutex.Unlock()
 
 	session := NewSession(s.id.Next())
+	s.cfg.start(session, time.Now())
 	s.sessions[session.id] = session
-	return session, nil
+	return session.clone(), nil
 }
 
 // Retrieve a session from the store.
@@ -59,12 +64,26 @@ func (s *MemoryStore) Read(id string) (*Session, error) {
 		return nil, fmt.Errorf("session %q has expired", id)
 	}
 
-	return session, nil
+	s.cfg.touch(session, time.Now())
+	return session.clone(), nil
 }
 
 // Update a session in the store.
+// Sessions deleted or regenerated since they were read are not saved again.
 func (s *MemoryStore) Update(session *Session) error {
-	// Pointers mean memory store sessions are always up to date.
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, ok := s.sessions[session.id]
+	if !ok {
+		return fmt.Errorf("MemoryStore.Update(): no session with id %q", session.id)
+	}
+	if stored.version != session.version {
+		return fmt.Errorf("MemoryStore.Update(): %w", ErrConflict)
+	}
+	session.version++
+	session.ClearChanged()
+	s.sessions[session.id] = session.clone()
 	return nil
 }
 
@@ -77,21 +96,17 @@ func (s *MemoryStore) Delete(id string) error {
 	return nil
 }
 
-// Extract session ID from request and return session.
-// If session does not exist create a new session.
-func (s *MemoryStore) FromRequest(r *http.Request) (*Session, error) {
-	cookie, err := r.Cookie(sessionCookie)
-	if err == nil {
-		session, err := s.Read(cookie.Value)
-		if err == nil {
-			return session, nil
-		}
-	}
-	session, err := s.Create()
-	if err != nil {
-		return nil, fmt.Errorf("MemoryStore.FromRequest; %w", err)
-	}
-	return session, nil
+// Move a session to a new ID and delete the old ID.
+func (s *MemoryStore) Regenerate(session *Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.sessions, session.id)
+	session.id = s.id.Next()
+	stored := session.clone()
+	stored.ClearChanged() // The changes are for the caller to save with Update().
+	s.sessions[session.id] = stored
+	return nil
 }
 
 // Delete expired sessions from the store.
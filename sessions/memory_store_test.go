@@ -0,0 +1,138 @@
+package sessions
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, func(t *testing.T, options ...StoreOption) SessionStore {
+		return NewMemoryStore(options...)
+	})
+}
+
+func TestMemoryStore_Copies(t *testing.T) {
+	store := NewMemoryStore()
+	created, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := store.Read(created.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.UserID = "u1"
+	session.Set("theme", "dark")
+
+	unsaved, err := store.Read(created.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Get[string](unsaved, "theme"); ok || unsaved.UserID != "" {
+		t.Errorf("MemoryStore.Read() returned changes that were not saved with Update()")
+	}
+
+	if err := store.Update(session); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.Read(created.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme, _ := Get[string](saved, "theme"); theme != "dark" || saved.UserID != "u1" {
+		t.Errorf("MemoryStore.Read() = %q, %q; expect the changes saved with Update()", saved.UserID, theme)
+	}
+
+	if err := store.Delete(created.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update(session); err == nil {
+		t.Errorf("MemoryStore.Update() error = nil; expect deleted sessions not to be saved again")
+	}
+}
+
+func TestMemoryStore_DeepCopies(t *testing.T) {
+	store := NewMemoryStore()
+	session, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cart := []string{"apple"}
+	session.Set("cart", cart)
+	if err := store.Update(session); err != nil {
+		t.Fatal(err)
+	}
+
+	// Modifying a saved value in place does not change the stored session.
+	cart[0] = "pear"
+	read, err := store.Read(session.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _ := Get[[]string](read, "cart")
+	if !slices.Equal(actual, []string{"apple"}) {
+		t.Errorf("Get[[]string]() = %v; expect %v", actual, []string{"apple"})
+	}
+
+	// Nor does modifying a value read from the store.
+	actual[0] = "plum"
+	read, err = store.Read(session.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, _ := Get[[]string](read, "cart"); !slices.Equal(actual, []string{"apple"}) {
+		t.Errorf("Get[[]string]() = %v; expect %v", actual, []string{"apple"})
+	}
+}
+
+// Run with -race to detect concurrent requests sharing session state.
+func TestMemoryStore_ConcurrentRequests(t *testing.T) {
+	store := NewMemoryStore()
+	// Count every saved visit, adding this request's visit to those saved by others.
+	countVisits := func(stored *Session, attempted *Session) *Session {
+		visits, _ := Get[int](stored, "visits")
+		merged := MergeOnConflict(stored, attempted)
+		merged.Set("visits", visits+1)
+		return merged
+	}
+	var logs bytes.Buffer
+	handler := withLogs(Middleware(store, WithOnConflict(countVisits))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := FromContext(r.Context())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		// Simulate MSALMiddleware refreshing the account on every request.
+		session.UserID = "u1"
+		session.Username = "alice@example.com"
+		visits, _ := Get[int](session, "visits")
+		session.Set("visits", visits+1)
+	})), &logs)
+
+	cookie := responseCookie(t, serve(handler), sessionCookie)
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			serve(handler, cookie)
+		})
+	}
+	wg.Wait()
+
+	session, err := store.Read(cookie.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Requests still conflicting after the last retry are discarded, and logged.
+	discarded := strings.Count(logs.String(), "discarded conflicting session changes")
+	if visits, _ := Get[int](session, "visits"); visits+discarded != 51 {
+		t.Errorf("Get[int]() = %d with %d discarded; expect 51 visits in total", visits, discarded)
+	}
+	if session.UserID != "u1" {
+		t.Errorf("Session.UserID = %q; expect %q", session.UserID, "u1")
+	}
+}
@@ -1,8 +1,11 @@
 package sessions
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/jrrdcnnlly/core/logging"
 )
@@ -10,15 +13,100 @@ import (
 // Session cookie name.
 const sessionCookie string = "session_id"
 
+// Middleware configuration.
+type middlewareConfig struct {
+	cookie     cookieConfig
+	onConflict ConflictResolver
+}
+
+// Middleware option.
+type MiddlewareOption func(cfg *middlewareConfig)
+
+// Optional session cookie name. Defaults to "session_id".
+func WithCookieName(name string) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.cookie.name = name
+	}
+}
+
+// Optional session cookie Path attribute. Defaults to "/".
+func WithCookiePath(path string) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.cookie.path = path
+	}
+}
+
+// Optional session cookie Domain attribute. Defaults to the request host.
+func WithCookieDomain(domain string) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.cookie.domain = domain
+	}
+}
+
+// Optional session cookie Secure attribute. Defaults to true.
+func WithCookieSecure(secure bool) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.cookie.secure = secure
+	}
+}
+
+// Optional session cookie HttpOnly attribute. Defaults to true.
+func WithCookieHTTPOnly(httpOnly bool) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.cookie.httpOnly = httpOnly
+	}
+}
+
+// Optional session cookie SameSite attribute. Defaults to http.SameSiteLaxMode.
+func WithCookieSameSite(sameSite http.SameSite) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.cookie.sameSite = sameSite
+	}
+}
+
+// Optional session cookie Max-Age attribute, refreshed on every response.
+// Defaults to 0, a cookie that expires when the browser is closed.
+func WithCookieMaxAge(maxAge time.Duration) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.cookie.maxAge = maxAge
+	}
+}
+
+// Optional resolver for session updates that conflict with a concurrent request,
+// e.g. MergeOnConflict. The update is retried with the resolved session a few times,
+// then discarded if other requests keep saving first.
+// Defaults to nil, conflicting changes are logged and discarded.
+func WithOnConflict(resolver ConflictResolver) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.onConflict = resolver
+	}
+}
+
 // Create a session handling middleware backed by the specified store.
-func Middleware(store SessionStore) func(http.Handler) http.Handler {
+// The session cookie is issued, or refreshed, before the response headers are written.
+func Middleware(store SessionStore, options ...MiddlewareOption) func(http.Handler) http.Handler {
+	// Init default config.
+	cfg := &middlewareConfig{
+		cookie: cookieConfig{
+			name:     sessionCookie,
+			path:     "/",
+			secure:   true,
+			httpOnly: true,
+			sameSite: http.SameSiteLaxMode,
+		},
+	}
+	// Apply options to config.
+	for _, option := range options {
+		option(cfg)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get logger from request context.
 			logger := logging.FromContextOrDefault(r.Context())
 
 			// Get or create session.
-			session, err := store.FromRequest(r)
+			session, chunks, err := load(store, r, cfg.cookie.name)
 			if err != nil {
 				logger.Error(
 					"failed to load session",
@@ -38,11 +126,43 @@ func Middleware(store SessionStore) func(http.Handler) http.Handler {
 
 			logger.Debug("loaded session")
 
-			// Pass the new logger to the next handler in the request context.
-			next.ServeHTTP(w, logging.Request(r, logger))
+			// Write the session cookie along with the response headers.
+			cw := newCookieWriter(w, &cfg.cookie, store, session, chunks, logger)
+
+			// Pass the session and new logger to the next handler in the request context.
+			next.ServeHTTP(cw, Request(logging.Request(r, logger), session))
+
+			// Handlers that write no response still need the cookie.
+			// Changes to sessions that could not be written to the cookie are discarded.
+			if err := cw.writeCookie(); err != nil {
+				return
+			}
+
+			// Delete destroyed sessions from the store.
+			if session.Destroyed() {
+				err = store.Delete(session.id)
+				if err != nil {
+					logger.Error(
+						"failed to delete session",
+						slog.Any("err", err),
+					)
+				}
+				return
+			}
 
-			// Save session back to store.
-			err = store.Update(session)
+			// Save session back to store, unless this request left it unchanged.
+			// Skipping unchanged sessions keeps read-only requests from conflicting with others.
+			if !session.Changed() {
+				return
+			}
+			err = update(store, session, cfg.onConflict)
+			if errors.Is(err, ErrConflict) {
+				logger.Warn(
+					"discarded conflicting session changes",
+					slog.Any("err", err),
+				)
+				return
+			}
 			if err != nil {
 				logger.Error(
 					"failed to save session",
@@ -52,3 +172,42 @@ func Middleware(store SessionStore) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// Read the session named by the session cookie.
+// If the cookie is missing, or the session does not exist, create a new session.
+// Also returns the number of cookies the session cookie was split across.
+func load(store SessionStore, r *http.Request, name string) (*Session, int, error) {
+	value, chunks := readCookie(r, name)
+	if chunks > 0 {
+		session, err := cookieSession(store, value)
+		if err == nil {
+			return session, chunks, nil
+		}
+	}
+	session, err := store.Create()
+	return session, chunks, err
+}
+
+// Save a session, resolving conflicting updates with resolver if it is not nil.
+func update(store SessionStore, session *Session, resolver ConflictResolver) error {
+	err := store.Update(session)
+	for range conflictRetries {
+		if resolver == nil || !errors.Is(err, ErrConflict) {
+			return err
+		}
+		// Sessions deleted or regenerated by another request can no longer be resolved.
+		stored, readErr := store.Read(session.id)
+		if readErr != nil {
+			return fmt.Errorf("%w: %w", ErrConflict, readErr)
+		}
+		resolved := resolver(stored, session)
+		if resolved == nil {
+			return nil
+		}
+		// Save over the stored version.
+		resolved.version = stored.version
+		err = store.Update(resolved)
+		session = resolved
+	}
+	return err
+}
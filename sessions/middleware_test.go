@@ -0,0 +1,273 @@
+package sessions
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrrdcnnlly/core/logging"
+)
+
+// Send a request through the middleware, optionally carrying session cookies.
+func serve(handler http.Handler, cookies ...*http.Cookie) *http.Response {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range cookies {
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+	}
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res.Result()
+}
+
+// Wrap a handler with a logger writing to logs, to check what the middleware logs.
+func withLogs(handler http.Handler, logs *bytes.Buffer) http.Handler {
+	logger := slog.New(slog.NewTextHandler(logs, nil))
+	return logging.Middleware(logging.WithLogger(logger))(handler)
+}
+
+// Return the named cookie set by a response.
+func responseCookie(t *testing.T, res *http.Response, name string) *http.Cookie {
+	t.Helper()
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	t.Fatalf("response has no %q cookie", name)
+	return nil
+}
+
+func TestMiddleware_Cookie(t *testing.T) {
+	store := NewMemoryStore()
+	handler := Middleware(
+		store,
+		WithCookieName("sid"),
+		WithCookiePath("/app"),
+		WithCookieDomain("example.com"),
+		WithCookieSecure(false),
+		WithCookieHTTPOnly(true),
+		WithCookieSameSite(http.SameSiteStrictMode),
+		WithCookieMaxAge(time.Hour),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	first := responseCookie(t, serve(handler, nil), "sid")
+	if first.Value == "" {
+		t.Fatalf("cookie value is empty; expect a session ID")
+	}
+	if first.Path != "/app" || first.Domain != "example.com" || first.Secure || !first.HttpOnly ||
+		first.SameSite != http.SameSiteStrictMode || first.MaxAge != 3600 {
+		t.Errorf("cookie = %+v; expect configured attributes", first)
+	}
+
+	// The same session is reused, and its cookie refreshed, on the next request.
+	second := responseCookie(t, serve(handler, first), "sid")
+	if second.Value != first.Value {
+		t.Errorf("cookie value = %q; expect %q", second.Value, first.Value)
+	}
+}
+
+func TestMiddleware_Destroy(t *testing.T) {
+	store := NewMemoryStore()
+	var destroy bool
+	handler := Middleware(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := FromContext(r.Context())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if destroy {
+			session.Destroy()
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	cookie := responseCookie(t, serve(handler, nil), sessionCookie)
+
+	destroy = true
+	cleared := responseCookie(t, serve(handler, cookie), sessionCookie)
+	if cleared.MaxAge >= 0 || cleared.Value != "" {
+		t.Errorf("cookie = %+v; expect the cookie to be cleared", cleared)
+	}
+	if _, err := store.Read(cookie.Value); err == nil {
+		t.Errorf("MemoryStore.Read() error = nil; expect the session to be deleted")
+	}
+}
+
+func TestMiddleware_Regenerate(t *testing.T) {
+	store := NewMemoryStore()
+	var regenerate bool
+	handler := Middleware(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := FromContext(r.Context())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		session.Set("visited", true)
+		if regenerate {
+			session.Regenerate()
+		}
+		http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+	}))
+
+	before := responseCookie(t, serve(handler, nil), sessionCookie)
+
+	regenerate = true
+	after := responseCookie(t, serve(handler, before), sessionCookie)
+	if after.Value == before.Value {
+		t.Fatalf("cookie value = %q; expect a new session ID", after.Value)
+	}
+	if _, err := store.Read(before.Value); err == nil {
+		t.Errorf("MemoryStore.Read() error = nil; expect the old session ID to be deleted")
+	}
+	session, err := store.Read(after.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if visited, _ := Get[bool](session, "visited"); !visited {
+		t.Errorf("session data was not moved to the new session ID")
+	}
+}
+
+func TestMiddleware_Conflict(t *testing.T) {
+	type Test struct {
+		name     string
+		resolver ConflictResolver
+		expect   map[string]string // Expected data after the request.
+	}
+
+	tests := []Test{
+		{"Conflicting changes should be discarded by default", nil, map[string]string{"theme": "dark"}},
+		{"Conflicting changes should be merged", MergeOnConflict, map[string]string{"theme": "dark", "lang": "en"}},
+		{"Conflicting changes should overwrite", OverwriteOnConflict, map[string]string{"lang": "en"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			var options []MiddlewareOption
+			if test.resolver != nil {
+				options = append(options, WithOnConflict(test.resolver))
+			}
+			handler := Middleware(store, options...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				session, err := FromContext(r.Context())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				// Simulate a concurrent request saving first.
+				other, err := store.Read(session.ID())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				other.Set("theme", "dark")
+				if err := store.Update(other); err != nil {
+					t.Error(err)
+				}
+				session.Set("lang", "en")
+			}))
+
+			cookie := responseCookie(t, serve(handler), sessionCookie)
+			session, err := store.Read(cookie.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := map[string]string{}
+			for _, key := range session.Keys() {
+				actual[key], _ = Get[string](session, key)
+			}
+			if !maps.Equal(actual, test.expect) {
+				t.Errorf("session data = %v; expect %v", actual, test.expect)
+			}
+		})
+	}
+}
+
+func TestMiddleware_ReadOnlyRace(t *testing.T) {
+	store := NewMemoryStore()
+	loaded := make(chan struct{})
+	read := make(chan struct{})
+	handler := Middleware(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := FromContext(r.Context())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if r.URL.Query().Has("write") {
+			session.Set("theme", "dark")
+			// Finish after a concurrent read-only request.
+			close(loaded)
+			<-read
+		}
+	}))
+
+	cookie := responseCookie(t, serve(handler), sessionCookie)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := httptest.NewRequest(http.MethodGet, "/?write", nil)
+		req.AddCookie(cookie)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	<-loaded
+	serve(handler, cookie)
+	close(read)
+	<-done
+
+	session, err := store.Read(cookie.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme, _ := Get[string](session, "theme"); theme != "dark" {
+		t.Errorf("Get[string]() = %q; expect a read-only request not to discard a concurrent write", theme)
+	}
+}
+
+// Session store whose sessions are deleted by a concurrent request when an update conflicts.
+type deletedOnConflictStore struct {
+	*MemoryStore
+}
+
+func (s deletedOnConflictStore) Update(session *Session) error {
+	err := s.MemoryStore.Update(session)
+	if errors.Is(err, ErrConflict) {
+		s.MemoryStore.Delete(session.ID())
+	}
+	return err
+}
+
+func TestMiddleware_ConflictDeleted(t *testing.T) {
+	store := deletedOnConflictStore{NewMemoryStore()}
+	var logs bytes.Buffer
+	handler := withLogs(Middleware(store, WithOnConflict(MergeOnConflict))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, err := FromContext(r.Context())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		// Simulate a concurrent request saving first.
+		other, err := store.Read(session.ID())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		other.Set("theme", "dark")
+		if err := store.MemoryStore.Update(other); err != nil {
+			t.Error(err)
+		}
+		session.Set("lang", "en")
+	})), &logs)
+
+	serve(handler)
+	if !strings.Contains(logs.String(), "discarded conflicting session changes") || strings.Contains(logs.String(), "level=ERROR") {
+		t.Errorf("logs = %q; expect the changes to a deleted session to be discarded as a conflict", logs.String())
+	}
+}
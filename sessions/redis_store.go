@@ -0,0 +1,309 @@
+package sessions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jrrdcnnlly/core/config"
+	"github.com/jrrdcnnlly/core/id"
+)
+
+// RedisStore configuration, set by StoreOptions and ignored by other stores.
+type redisStoreConfig struct {
+	keyPrefix      string
+	poolSize       int
+	password       *config.Secret[string] // By pointer, so printing the config never shows the password.
+	dialTimeout    time.Duration
+	commandTimeout time.Duration
+}
+
+// Returned when a session is not in the store.
+var errNoSession = errors.New("no session")
+
+// Session store backed by a server speaking the Redis protocol,
+// such as Redis, Valkey or KeyDB, shared by every replica of an application.
+// Sessions are removed by the server when they expire.
+// Create with NewRedisStore().
+type RedisStore struct {
+	id        *id.RandomGenerator
+	cfg       *storeConfig
+	pool      *respPool
+	keyPrefix string
+}
+
+// Optional prefix added to RedisStore session keys. Defaults to "session:".
+func WithRedisKeyPrefix(prefix string) StoreOption {
+	return withRedis(func(cfg *redisStoreConfig) {
+		cfg.keyPrefix = prefix
+	})
+}
+
+// Optional maximum number of open RedisStore connections. Defaults to 10.
+func WithRedisPoolSize(size int) StoreOption {
+	return withRedis(func(cfg *redisStoreConfig) {
+		cfg.poolSize = size
+	})
+}
+
+// Optional password sent by RedisStore with AUTH on each new connection.
+func WithRedisPassword(password string) StoreOption {
+	return withRedis(func(cfg *redisStoreConfig) {
+		secret := config.NewSecret(password)
+		cfg.password = &secret
+	})
+}
+
+// Optional RedisStore connection timeout. Defaults to 5 seconds.
+func WithRedisDialTimeout(timeout time.Duration) StoreOption {
+	return withRedis(func(cfg *redisStoreConfig) {
+		cfg.dialTimeout = timeout
+	})
+}
+
+// Optional time allowed for each RedisStore command, including reading its reply. Defaults to 5 seconds.
+// Set to 0 to wait indefinitely.
+func WithRedisCommandTimeout(timeout time.Duration) StoreOption {
+	return withRedis(func(cfg *redisStoreConfig) {
+		cfg.commandTimeout = timeout
+	})
+}
+
+// Create a StoreOption changing the RedisStore configuration.
+func withRedis(option func(cfg *redisStoreConfig)) StoreOption {
+	return func(cfg *storeConfig) {
+		if cfg.redis == nil {
+			cfg.redis = newRedisStoreConfig()
+		}
+		option(cfg.redis)
+	}
+}
+
+// Create the default RedisStore configuration.
+func newRedisStoreConfig() *redisStoreConfig {
+	return &redisStoreConfig{
+		keyPrefix:      "session:",
+		poolSize:       10,
+		password:       &config.Secret[string]{},
+		dialTimeout:    5 * time.Second,
+		commandTimeout: 5 * time.Second,
+	}
+}
+
+// Create a new RedisStore connecting to the server at addr, e.g. "localhost:6379".
+// Connections are opened when first needed.
+func NewRedisStore(addr string, options ...StoreOption) *RedisStore {
+	cfg := newStoreConfig(options...)
+	redis := cfg.redis
+	if redis == nil {
+		redis = newRedisStoreConfig()
+	}
+
+	return &RedisStore{
+		id:  id.NewRandomGenerator(),
+		cfg: cfg,
+		pool: newRESPPool(redis.poolSize, func() (*respConn, error) {
+			return dialRESP("tcp", addr, redis.dialTimeout, redis.commandTimeout, redis.password.Reveal())
+		}),
+		keyPrefix: redis.keyPrefix,
+	}
+}
+
+// Create a new session in the store.
+func (s *RedisStore) Create() (*Session, error) {
+	session := NewSession(s.id.Next())
+	s.cfg.start(session, time.Now())
+	if err := s.write(session); err != nil {
+		return nil, fmt.Errorf("RedisStore.Create(): %w", err)
+	}
+	return session, nil
+}
+
+// Retrieve a session from the store.
+func (s *RedisStore) Read(id string) (*Session, error) {
+	reply, err := s.pool.do("GET", s.keyPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("RedisStore.Read(): %w", err)
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w with id %q", errNoSession, id)
+	}
+	session := &Session{}
+	if err := json.Unmarshal([]byte(data), session); err != nil {
+		return nil, fmt.Errorf("RedisStore.Read(): %w", err)
+	}
+
+	// Reads only extend the TTL when idle timeouts slide, so the stored expiry may be stale.
+	// Touch before checking expiry; the server removes sessions idle for longer.
+	s.cfg.touch(session, time.Now())
+	if session.Expired() {
+		return nil, fmt.Errorf("session %q has expired", id)
+	}
+
+	if s.cfg.idleTimeout > 0 {
+		ttl := time.Until(session.Expires).Milliseconds()
+		if _, err := s.pool.do("PEXPIRE", s.keyPrefix+id, strconv.FormatInt(ttl, 10)); err != nil {
+			return nil, fmt.Errorf("RedisStore.Read(): %w", err)
+		}
+	}
+	return session, nil
+}
+
+// Update a session in the store.
+// Sessions deleted or regenerated since they were read are not saved again.
+func (s *RedisStore) Update(session *Session) error {
+	expected := session.version
+	session.version++
+	if err := s.update(session, expected); err != nil {
+		session.version = expected
+		return fmt.Errorf("RedisStore.Update(): %w", err)
+	}
+	session.ClearChanged()
+	return nil
+}
+
+// Delete a session from the store.
+func (s *RedisStore) Delete(id string) error {
+	if _, err := s.pool.do("DEL", s.keyPrefix+id); err != nil {
+		return fmt.Errorf("RedisStore.Delete(): %w", err)
+	}
+	return nil
+}
+
+// Move a session to a new ID and delete the old ID in a single transaction.
+func (s *RedisStore) Regenerate(session *Session) error {
+	old := session.id
+	session.id = s.id.Next()
+	set, err := s.set(session)
+	if err != nil {
+		session.id = old
+		return fmt.Errorf("RedisStore.Regenerate(): %w", err)
+	}
+
+	conn, err := s.pool.get()
+	if err != nil {
+		session.id = old
+		return fmt.Errorf("RedisStore.Regenerate(): %w", err)
+	}
+	replies, err := conn.pipeline([]string{"MULTI"}, set, []string{"DEL", s.keyPrefix + old}, []string{"EXEC"})
+	if err == nil {
+		err = execError(replies[len(replies)-1])
+	}
+	s.pool.put(conn, err)
+	if err != nil {
+		session.id = old
+		return fmt.Errorf("RedisStore.Regenerate(): %w", err)
+	}
+	return nil
+}
+
+// Close the idle connections to the server.
+func (s *RedisStore) Close() error {
+	if err := s.pool.close(); err != nil {
+		return fmt.Errorf("RedisStore.Close(): %w", err)
+	}
+	return nil
+}
+
+// Save a session if its stored version is still expected.
+// The stored version is watched so the save is aborted if another client changes it first.
+func (s *RedisStore) update(session *Session, expected int64) error {
+	set, err := s.set(session)
+	if err != nil {
+		return err
+	}
+	// Reads extending the TTL with PEXPIRE also abort transactions watching the key,
+	// so retry while the stored version is unchanged.
+	for {
+		retry, err := s.tryUpdate(session.id, set, expected)
+		if !retry {
+			return err
+		}
+	}
+}
+
+// Run set if the stored version of session id is still expected.
+// Returns true if the transaction was aborted and should be retried.
+func (s *RedisStore) tryUpdate(id string, set []string, expected int64) (bool, error) {
+	conn, err := s.pool.get()
+	if err != nil {
+		return false, err
+	}
+
+	// Only connection errors are passed back to the pool.
+	key := s.keyPrefix + id
+	replies, err := conn.pipeline([]string{"WATCH", key}, []string{"GET", key})
+	if err != nil {
+		s.pool.put(conn, err)
+		return false, err
+	}
+	data, ok := replies[1].(string)
+	stored := &Session{}
+	if ok {
+		err = json.Unmarshal([]byte(data), stored)
+	}
+	if !ok || err != nil || stored.version != expected {
+		_, unwatchErr := conn.do("UNWATCH")
+		s.pool.put(conn, unwatchErr)
+		switch {
+		case !ok:
+			return false, fmt.Errorf("%w with id %q", errNoSession, id)
+		case err != nil:
+			return false, err
+		default:
+			return false, ErrConflict
+		}
+	}
+
+	replies, err = conn.pipeline([]string{"MULTI"}, set, []string{"EXEC"})
+	s.pool.put(conn, err)
+	if err != nil {
+		return false, err
+	}
+	// EXEC replies with null if the watched key changed.
+	if replies[len(replies)-1] == nil {
+		return true, nil
+	}
+	return false, execError(replies[len(replies)-1])
+}
+
+// Save a session, or delete it if it has already expired.
+func (s *RedisStore) write(session *Session) error {
+	args, err := s.set(session)
+	if err != nil {
+		return err
+	}
+	_, err = s.pool.do(args...)
+	return err
+}
+
+// Return the command saving a session with a TTL matching its expiry.
+// Sessions that have already expired are deleted instead.
+func (s *RedisStore) set(session *Session) ([]string, error) {
+	ttl := time.Until(session.Expires).Milliseconds()
+	if ttl <= 0 {
+		return []string{"DEL", s.keyPrefix + session.id}, nil
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"SET", s.keyPrefix + session.id, string(data), "PX", strconv.FormatInt(ttl, 10)}, nil
+}
+
+// Return the first error reply among the results of EXEC.
+func execError(reply any) error {
+	results, ok := reply.([]any)
+	if !ok {
+		return errors.New("transaction aborted")
+	}
+	for _, result := range results {
+		if err, ok := result.(respError); ok {
+			return err
+		}
+	}
+	return nil
+}
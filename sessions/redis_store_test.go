@@ -0,0 +1,435 @@
+package sessions
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRedisStore(t *testing.T) {
+	testStore(t, func(t *testing.T, options ...StoreOption) SessionStore {
+		server := newFakeRESPServer(t, "")
+		return newTestRedisStore(t, server, options...)
+	})
+}
+
+func TestRedisStore_TTL(t *testing.T) {
+	server := newFakeRESPServer(t, "secret")
+	store := newTestRedisStore(t, server, WithRedisKeyPrefix("app:"), WithRedisPassword("secret"), WithLifetime(time.Hour))
+
+	session, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, ok := server.lookup("app:" + session.ID())
+	if !ok {
+		t.Fatalf("session not stored under the key prefix")
+	}
+	if ttl := time.Until(value.expires); ttl < 59*time.Minute || ttl > time.Hour {
+		t.Errorf("TTL = %v; expect the session lifetime", ttl)
+	}
+
+	// Expired sessions are deleted rather than stored.
+	session.Expires = time.Now().Add(-time.Minute)
+	if err := store.Update(session); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := server.lookup("app:" + session.ID()); ok {
+		t.Errorf("expired session kept by the server")
+	}
+}
+
+func TestRedisStore_Read(t *testing.T) {
+	type Test struct {
+		name         string
+		options      []StoreOption
+		expectWrites int64
+		expectTTL    time.Duration
+	}
+
+	tests := []Test{
+		{name: "Read() should leave the key alone without an idle timeout", options: []StoreOption{WithLifetime(time.Hour)}, expectWrites: 0, expectTTL: time.Hour},
+		{name: "Read() should extend the TTL with an idle timeout", options: []StoreOption{WithIdleTimeout(2 * time.Hour)}, expectWrites: 1, expectTTL: 2 * time.Hour},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newFakeRESPServer(t, "")
+			store := newTestRedisStore(t, server, test.options...)
+			session, err := store.Create()
+			if err != nil {
+				t.Fatal(err)
+			}
+			key := "session:" + session.ID()
+
+			// Shorten the TTL to see whether reads extend it.
+			server.mutex.Lock()
+			before := server.values[key]
+			before.expires = time.Now().Add(time.Minute)
+			server.values[key] = before
+			revision := server.revision[key]
+			server.mutex.Unlock()
+
+			if _, err := store.Read(session.ID()); err != nil {
+				t.Fatal(err)
+			}
+			after, ok := server.lookup(key)
+			if !ok {
+				t.Fatalf("session not kept by the server")
+			}
+			if after.data != before.data {
+				t.Errorf("RedisStore.Read() rewrote the session; expect the stored data to be kept")
+			}
+			server.mutex.Lock()
+			writes := server.revision[key] - revision
+			server.mutex.Unlock()
+			if writes != test.expectWrites {
+				t.Errorf("RedisStore.Read() modified the key %d times; expect %d", writes, test.expectWrites)
+			}
+			if test.expectWrites > 0 {
+				if ttl := time.Until(after.expires); ttl < test.expectTTL-time.Minute || ttl > test.expectTTL {
+					t.Errorf("TTL = %v; expect %v", ttl, test.expectTTL)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisStore_UpdateAfterRead(t *testing.T) {
+	server := newFakeRESPServer(t, "")
+	store := newTestRedisStore(t, server, WithIdleTimeout(time.Hour))
+	session, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := store.Read(session.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Extending the TTL does not change the stored version, so updates still succeed.
+	if _, err := store.Read(session.ID()); err != nil {
+		t.Fatal(err)
+	}
+	read.Set("theme", "dark")
+	if err := store.Update(read); err != nil {
+		t.Errorf("RedisStore.Update() error = %v; expect reads not to conflict", err)
+	}
+}
+
+func TestRedisStore_Auth(t *testing.T) {
+	type Test struct {
+		password string
+		expect   bool
+	}
+
+	tests := []Test{
+		{"secret", true},
+		{"wrong", false},
+	}
+
+	server := newFakeRESPServer(t, "secret")
+	for _, test := range tests {
+		store := newTestRedisStore(t, server, WithRedisPassword(test.password))
+		if _, err := store.Create(); (err == nil) != test.expect {
+			t.Errorf("RedisStore.Create() error = %v with password %q; expect success = %v", err, test.password, test.expect)
+		}
+	}
+}
+
+func TestRedisStore_PasswordRedacted(t *testing.T) {
+	cfg := newStoreConfig(WithRedisPassword("hunter2"))
+	for _, format := range []string{"%v", "%+v", "%#v"} {
+		if actual := fmt.Sprintf(format, cfg.redis); strings.Contains(actual, "hunter2") {
+			t.Errorf("fmt.Sprintf(%q) = %s; expect the password to be hidden", format, actual)
+		}
+	}
+}
+
+func TestRedisStore_CommandTimeout(t *testing.T) {
+	// Accept connections but never reply.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	store := NewRedisStore(listener.Addr().String(), WithRedisCommandTimeout(50*time.Millisecond))
+	t.Cleanup(func() { store.Close() })
+	if _, err := store.Read("id"); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("RedisStore.Read() error = %v; expect %v", err, os.ErrDeadlineExceeded)
+	}
+}
+
+func TestRedisStore_Pool(t *testing.T) {
+	server := newFakeRESPServer(t, "")
+	store := newTestRedisStore(t, server, WithRedisPoolSize(2))
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			session, err := store.Create()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, err := store.Read(session.ID()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if server.maxConns > 2 {
+		t.Errorf("server saw %d concurrent connections; expect at most 2", server.maxConns)
+	}
+}
+
+// Create a RedisStore connected to a fake server.
+func newTestRedisStore(t *testing.T, server *fakeRESPServer, options ...StoreOption) *RedisStore {
+	store := NewRedisStore(server.listener.Addr().String(), options...)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// Value held by a fakeRESPServer.
+type fakeRESPValue struct {
+	data    string
+	expires time.Time // Zero for no expiry.
+}
+
+// In-process stand-in for a Redis server, implementing the commands used by RedisStore.
+type fakeRESPServer struct {
+	listener net.Listener
+	password string
+	values   map[string]fakeRESPValue
+	revision map[string]int64 // Number of times each key has been modified, for WATCH.
+	conns    int              // Open connections.
+	maxConns int              // Most connections open at once.
+	mutex    sync.Mutex       // Sync access to values, revision and conns.
+	commands sync.Mutex       // Serialize commands, so transactions are atomic.
+}
+
+// Start a fake server, requiring AUTH if password is not empty.
+func newFakeRESPServer(t *testing.T, password string) *fakeRESPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeRESPServer{
+		listener: listener,
+		password: password,
+		values:   map[string]fakeRESPValue{},
+		revision: map[string]int64{},
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+// Return an unexpired value.
+func (s *fakeRESPServer) lookup(key string) (fakeRESPValue, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	value, ok := s.values[key]
+	if ok && !value.expires.IsZero() && !time.Now().Before(value.expires) {
+		delete(s.values, key)
+		return fakeRESPValue{}, false
+	}
+	return value, ok
+}
+
+// Handle the commands sent on a connection.
+func (s *fakeRESPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	s.mutex.Lock()
+	s.conns++
+	s.maxConns = max(s.maxConns, s.conns)
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		s.conns--
+		s.mutex.Unlock()
+	}()
+
+	reader := bufio.NewReader(conn)
+	authenticated := s.password == ""
+	var queue [][]string          // Commands queued by MULTI, nil outside a transaction.
+	watched := map[string]int64{} // Revisions of watched keys.
+	for {
+		args, err := readFakeCommand(reader)
+		if err != nil {
+			return
+		}
+		s.commands.Lock()
+		var reply string
+		switch command := strings.ToUpper(args[0]); {
+		case command == "AUTH":
+			authenticated = args[1] == s.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case command == "WATCH":
+			s.mutex.Lock()
+			for _, key := range args[1:] {
+				watched[key] = s.revision[key]
+			}
+			s.mutex.Unlock()
+			reply = "+OK\r\n"
+		case command == "UNWATCH":
+			clear(watched)
+			reply = "+OK\r\n"
+		case command == "MULTI":
+			queue = [][]string{}
+			reply = "+OK\r\n"
+		case command == "EXEC" && s.changed(watched):
+			reply = "*-1\r\n"
+			queue = nil
+			clear(watched)
+		case command == "EXEC":
+			reply = fmt.Sprintf("*%d\r\n", len(queue))
+			for _, queued := range queue {
+				reply += s.exec(queued)
+			}
+			queue = nil
+			clear(watched)
+		case queue != nil:
+			queue = append(queue, args)
+			reply = "+QUEUED\r\n"
+		default:
+			reply = s.exec(args)
+		}
+		s.commands.Unlock()
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// Have any watched keys been modified?
+func (s *fakeRESPServer) changed(watched map[string]int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key, revision := range watched {
+		if s.revision[key] != revision {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute a command and return its encoded reply.
+func (s *fakeRESPServer) exec(args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		value, ok := s.lookup(args[1])
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value.data), value.data)
+	case "SET":
+		value := fakeRESPValue{data: args[2]}
+		if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+			ms, err := strconv.Atoi(args[4])
+			if err != nil {
+				return "-ERR value is not an integer\r\n"
+			}
+			value.expires = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		s.mutex.Lock()
+		s.values[args[1]] = value
+		s.revision[args[1]]++
+		s.mutex.Unlock()
+		return "+OK\r\n"
+	case "PEXPIRE":
+		ms, err := strconv.Atoi(args[2])
+		if err != nil {
+			return "-ERR value is not an integer\r\n"
+		}
+		if _, ok := s.lookup(args[1]); !ok {
+			return ":0\r\n"
+		}
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		value := s.values[args[1]]
+		value.expires = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		s.values[args[1]] = value
+		// Like Redis, changing the TTL aborts transactions watching the key.
+		s.revision[args[1]]++
+		return ":1\r\n"
+	case "DEL":
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.values[key]; ok {
+				delete(s.values, key)
+				s.revision[key]++
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+// Read a command sent as an array of bulk strings.
+func readFakeCommand(reader *bufio.Reader) ([]string, error) {
+	var count int
+	if _, err := fmt.Fscanf(reader, "*%d\r\n", &count); err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(reader, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		buffer := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buffer); err != nil {
+			return nil, err
+		}
+		args[i] = string(buffer[:size])
+	}
+	return args, nil
+}
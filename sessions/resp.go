@@ -0,0 +1,244 @@
+package sessions
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Error reply returned by a RESP server.
+type respError string
+
+// Implement the error interface.
+func (e respError) Error() string {
+	return string(e)
+}
+
+// Connection to a server speaking the Redis serialization protocol (RESP).
+type respConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+	timeout time.Duration // Time allowed for each command and its reply, 0 for no limit.
+}
+
+// Send a command and read its reply.
+// Replies are strings, int64s, []any for arrays, or nil for null replies.
+func (c *respConn) do(args ...string) (any, error) {
+	if err := c.deadline(); err != nil {
+		return nil, err
+	}
+	c.send(args...)
+	if err := c.writer.Flush(); err != nil {
+		return nil, err
+	}
+	return c.receive()
+}
+
+// Send several commands at once and read their replies.
+// All replies are read even if one is an error, the first error is returned.
+func (c *respConn) pipeline(commands ...[]string) ([]any, error) {
+	if err := c.deadline(); err != nil {
+		return nil, err
+	}
+	for _, args := range commands {
+		c.send(args...)
+	}
+	if err := c.writer.Flush(); err != nil {
+		return nil, err
+	}
+	replies := make([]any, len(commands))
+	var first error
+	for i := range commands {
+		reply, err := c.receive()
+		var respErr respError
+		if err != nil && !errors.As(err, &respErr) {
+			return nil, err
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+		replies[i] = reply
+	}
+	return replies, first
+}
+
+// Limit the time allowed to send the next commands and read their replies.
+// Connections that time out are closed when returned to the pool.
+func (c *respConn) deadline() error {
+	if c.timeout <= 0 {
+		return nil
+	}
+	return c.conn.SetDeadline(time.Now().Add(c.timeout))
+}
+
+// Buffer a command as an array of bulk strings.
+// Write errors are returned when the buffer is flushed.
+func (c *respConn) send(args ...string) {
+	fmt.Fprintf(c.writer, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.writer, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+}
+
+// Read a single reply. Error replies are returned as a respError.
+func (c *respConn) receive() (any, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+	switch kind, body := line[0], line[1:]; kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, respError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		buffer := make([]byte, size+2) // Include the trailing \r\n.
+		if _, err := io.ReadFull(c.reader, buffer); err != nil {
+			return nil, err
+		}
+		return string(buffer[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(body)
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		values := make([]any, size)
+		for i := range values {
+			value, err := c.receive()
+			var respErr respError
+			if err != nil && !errors.As(err, &respErr) {
+				return nil, err
+			}
+			if err != nil {
+				value = respErr
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply %q", line)
+	}
+}
+
+// Read a line without its trailing \r\n.
+func (c *respConn) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("malformed reply %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+// Pool of RESP connections, limited to a maximum number of open connections.
+type respPool struct {
+	dial  func() (*respConn, error)
+	slots chan struct{}  // Holds a value for each open connection.
+	idle  chan *respConn // Open connections not in use.
+}
+
+// Create a pool of at most size connections opened with dial.
+func newRESPPool(size int, dial func() (*respConn, error)) *respPool {
+	return &respPool{
+		dial:  dial,
+		slots: make(chan struct{}, size),
+		idle:  make(chan *respConn, size),
+	}
+}
+
+// Return an idle connection, or open a new one.
+// Blocks while the maximum number of connections are in use.
+func (p *respPool) get() (*respConn, error) {
+	select {
+	case conn := <-p.idle:
+		return conn, nil
+	default:
+	}
+
+	select {
+	case conn := <-p.idle:
+		return conn, nil
+	case p.slots <- struct{}{}:
+	}
+	conn, err := p.dial()
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Return a connection to the pool.
+// Connections that failed with anything other than an error reply are closed.
+func (p *respPool) put(conn *respConn, err error) {
+	var respErr respError
+	if err != nil && !errors.As(err, &respErr) {
+		conn.conn.Close()
+		<-p.slots
+		return
+	}
+	p.idle <- conn // Never blocks, there are at most size connections.
+}
+
+// Send a command on a pooled connection.
+func (p *respPool) do(args ...string) (any, error) {
+	conn, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.do(args...)
+	p.put(conn, err)
+	return reply, err
+}
+
+// Close the idle connections.
+func (p *respPool) close() error {
+	var errs []error
+	for {
+		select {
+		case conn := <-p.idle:
+			errs = append(errs, conn.conn.Close())
+			<-p.slots
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// Open a RESP connection, authenticating if a password is given.
+// Each command on the connection must complete within commandTimeout, if it is not 0.
+func dialRESP(network string, addr string, dialTimeout time.Duration, commandTimeout time.Duration, password string) (*respConn, error) {
+	conn, err := net.DialTimeout(network, addr, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &respConn{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		writer:  bufio.NewWriter(conn),
+		timeout: commandTimeout,
+	}
+	if password != "" {
+		if _, err := c.do("AUTH", password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
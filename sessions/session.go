@@ -1,14 +1,31 @@
 package sessions
 
 import (
+	"encoding/json"
+	"maps"
+	"slices"
 	"time"
 )
 
 type Session struct {
-	id       string
-	Expires  time.Time
-	UserID   string
-	Username string
+	id         string
+	destroyed  bool
+	regenerate bool
+	version    int64
+	data       map[string]any      // Arbitrary session data by key.
+	changed    map[string]struct{} // Data keys set or deleted since the session was loaded.
+	loaded     account             // User fields as loaded, to detect changes.
+	Created    time.Time           // When the session was created.
+	Accessed   time.Time           // When the session was last read from its store.
+	Expires    time.Time           // When the session expires, maintained by its store.
+	UserID     string
+	Username   string
+}
+
+// User fields of a session.
+type account struct {
+	userID   string
+	username string
 }
 
 // Create a new empty session with the given iD.
@@ -18,7 +35,63 @@ func NewSession(id string) *Session {
 	}
 }
 
+// Return the session ID.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Return the version of the session, incremented by its store on every Update().
+// Stores use it to detect concurrent updates, see ErrConflict.
+func (s *Session) Version() int64 {
+	return s.version
+}
+
 // Is the session expired?
 func (s *Session) Expired() bool {
 	return s.Expires.Before(time.Now())
 }
+
+// Mark the session for deletion.
+// The session middleware deletes it from the store and clears the session cookie.
+func (s *Session) Destroy() {
+	s.destroyed = true
+}
+
+// Has the session been marked for deletion?
+func (s *Session) Destroyed() bool {
+	return s.destroyed
+}
+
+// Mark the session to be moved to a new ID, e.g. after login to prevent session fixation.
+// The session middleware regenerates it with its store before writing the session cookie.
+func (s *Session) Regenerate() {
+	s.regenerate = true
+}
+
+// Return a copy of the session that can be modified independently.
+// Data values are copied through JSON, as a persistent store would save them,
+// so values modified in place, such as slices and maps, are not shared with the copy.
+// Values that cannot be encoded as JSON are shared.
+func (s *Session) clone() *Session {
+	clone := *s
+	if s.data != nil {
+		clone.data = make(map[string]any, len(s.data))
+		for key, value := range s.data {
+			clone.data[key] = copyValue(value)
+		}
+	}
+	clone.changed = maps.Clone(s.changed)
+	return &clone
+}
+
+// Return a copy of a data value, encoded as JSON.
+func copyValue(value any) any {
+	if raw, ok := value.(json.RawMessage); ok {
+		return slices.Clone(raw)
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	return json.RawMessage(raw)
+}
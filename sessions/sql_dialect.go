@@ -0,0 +1,81 @@
+package sessions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQL differences between databases used by SQLStore.
+type Dialect interface {
+	// Return the placeholder for the nth query argument, starting from 1.
+	Placeholder(n int) string
+	// Return a statement inserting columns into table, or updating the row if key already exists.
+	// Arguments are given in column order.
+	Upsert(table string, key string, columns []string) string
+}
+
+var (
+	// SQLite dialect, using "?" placeholders.
+	SQLite Dialect = sqliteDialect{}
+	// Postgres dialect, using "$1" placeholders.
+	Postgres Dialect = postgresDialect{}
+)
+
+type sqliteDialect struct{}
+
+// Implement the Dialect interface.
+func (sqliteDialect) Placeholder(n int) string {
+	return "?"
+}
+
+// Implement the Dialect interface.
+func (d sqliteDialect) Upsert(table string, key string, columns []string) string {
+	return onConflictUpsert(d, table, key, columns)
+}
+
+type postgresDialect struct{}
+
+// Implement the Dialect interface.
+func (postgresDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// Implement the Dialect interface.
+func (d postgresDialect) Upsert(table string, key string, columns []string) string {
+	return onConflictUpsert(d, table, key, columns)
+}
+
+// Build an "INSERT ... ON CONFLICT DO UPDATE" statement, supported by SQLite and Postgres.
+func onConflictUpsert(d Dialect, table string, key string, columns []string) string {
+	placeholders := make([]string, len(columns))
+	var updates []string
+	for i, column := range columns {
+		placeholders[i] = d.Placeholder(i + 1)
+		if column != key {
+			updates = append(updates, column+" = excluded."+column)
+		}
+	}
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s",
+		table,
+		strings.Join(columns, ", "),
+		strings.Join(placeholders, ", "),
+		key,
+		strings.Join(updates, ", "),
+	)
+}
+
+// Replace the "?" placeholders in query with those of the dialect.
+func bind(d Dialect, query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		b.WriteString(d.Placeholder(n))
+	}
+	return b.String()
+}
@@ -0,0 +1,291 @@
+package sessions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jrrdcnnlly/core/id"
+)
+
+// Name of the table sessions are stored in.
+const sessionTable string = "sessions"
+
+// Name of the table recording applied schema migrations.
+const migrationTable string = "sessions_migrations"
+
+// Columns written for each session, in argument order.
+var sessionColumns = []string{"id", "user_id", "expires", "data", "version"}
+
+// Schema migrations, applied in order.
+// Append new migrations, never edit applied ones.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL DEFAULT '',
+		expires BIGINT NOT NULL,
+		data TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS sessions_expires ON sessions (expires)`,
+	`CREATE INDEX IF NOT EXISTS sessions_user_id ON sessions (user_id)`,
+	`ALTER TABLE sessions ADD COLUMN version BIGINT NOT NULL DEFAULT 0`,
+}
+
+// Session store persisting sessions in a database/sql database.
+// Call Migrate() to create the schema before use.
+// Create with NewSQLStore().
+type SQLStore struct {
+	id      *id.RandomGenerator
+	cfg     *storeConfig
+	db      *sql.DB
+	dialect Dialect
+}
+
+// Create a new SQLStore using the given database and dialect.
+func NewSQLStore(db *sql.DB, dialect Dialect, options ...StoreOption) *SQLStore {
+	store := &SQLStore{
+		id:      id.NewRandomGenerator(),
+		cfg:     newStoreConfig(options...),
+		db:      db,
+		dialect: dialect,
+	}
+
+	// Every hour run cleanup to remove expired sessions.
+	ticker := time.NewTicker(time.Hour)
+	go func() {
+		for range ticker.C {
+			store.Cleanup()
+		}
+	}()
+
+	return store
+}
+
+// Create or update the session tables, applying any pending migrations.
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("SQLStore.Migrate(): %w", err)
+	}
+	defer tx.Rollback() // Ignore returned error, a no-op after commit.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+migrationTable+" (version INTEGER NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("SQLStore.Migrate(): %w", err)
+	}
+	var version int
+	err = tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM "+migrationTable).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("SQLStore.Migrate(): %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			return fmt.Errorf("SQLStore.Migrate(): migration %d: %w", i+1, err)
+		}
+	}
+	if version < len(migrations) {
+		_, err := tx.ExecContext(ctx, bind(s.dialect, "INSERT INTO "+migrationTable+" (version) VALUES (?)"), len(migrations))
+		if err != nil {
+			return fmt.Errorf("SQLStore.Migrate(): %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("SQLStore.Migrate(): %w", err)
+	}
+	return nil
+}
+
+// Create a new session in the store.
+func (s *SQLStore) Create() (*Session, error) {
+	session := NewSession(s.id.Next())
+	s.cfg.start(session, time.Now())
+	if err := s.write(s.db, session); err != nil {
+		return nil, fmt.Errorf("SQLStore.Create(): %w", err)
+	}
+	return session, nil
+}
+
+// Retrieve a session from the store.
+func (s *SQLStore) Read(id string) (*Session, error) {
+	var data []byte
+	err := s.db.QueryRow(bind(s.dialect, "SELECT data FROM "+sessionTable+" WHERE id = ?"), id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("no session with id %q", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("SQLStore.Read(): %w", err)
+	}
+	session := &Session{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("SQLStore.Read(): %w", err)
+	}
+
+	if session.Expired() {
+		return nil, fmt.Errorf("session %q has expired", id)
+	}
+
+	// Save the access time when idle timeouts slide, otherwise reads leave the row alone.
+	// The row is not saved if another request has updated the session since it was read.
+	s.cfg.touch(session, time.Now())
+	if s.cfg.idleTimeout > 0 {
+		if _, err := s.update(session, session.version); err != nil {
+			return nil, fmt.Errorf("SQLStore.Read(): %w", err)
+		}
+	}
+	return session, nil
+}
+
+// Return the unexpired sessions of a user, e.g. to list or sign out their devices.
+func (s *SQLStore) ReadByUser(userID string) ([]*Session, error) {
+	rows, err := s.db.Query(
+		bind(s.dialect, "SELECT data FROM "+sessionTable+" WHERE user_id = ? AND expires > ? ORDER BY expires"),
+		userID,
+		time.Now().UnixMilli(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("SQLStore.ReadByUser(): %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*Session
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("SQLStore.ReadByUser(): %w", err)
+		}
+		session := &Session{}
+		if err := json.Unmarshal(data, session); err != nil {
+			return nil, fmt.Errorf("SQLStore.ReadByUser(): %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("SQLStore.ReadByUser(): %w", err)
+	}
+	return sessions, nil
+}
+
+// Update a session in the store.
+// Sessions deleted or regenerated since they were read are not saved again.
+func (s *SQLStore) Update(session *Session) error {
+	expected := session.version
+	session.version++
+	updated, err := s.update(session, expected)
+	if err != nil || !updated {
+		session.version = expected
+	}
+	if err != nil {
+		return fmt.Errorf("SQLStore.Update(): %w", err)
+	}
+	if updated {
+		session.ClearChanged()
+		return nil
+	}
+
+	// Distinguish a conflicting update from a missing session.
+	var version int64
+	err = s.db.QueryRow(bind(s.dialect, "SELECT version FROM "+sessionTable+" WHERE id = ?"), session.id).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("SQLStore.Update(): no session with id %q", session.id)
+	}
+	if err != nil {
+		return fmt.Errorf("SQLStore.Update(): %w", err)
+	}
+	return fmt.Errorf("SQLStore.Update(): %w", ErrConflict)
+}
+
+// Delete a session from the store.
+func (s *SQLStore) Delete(id string) error {
+	_, err := s.db.Exec(bind(s.dialect, "DELETE FROM "+sessionTable+" WHERE id = ?"), id)
+	if err != nil {
+		return fmt.Errorf("SQLStore.Delete(): %w", err)
+	}
+	return nil
+}
+
+// Move a session to a new ID and delete the old ID.
+func (s *SQLStore) Regenerate(session *Session) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("SQLStore.Regenerate(): %w", err)
+	}
+	defer tx.Rollback() // Ignore returned error, a no-op after commit.
+
+	old := session.id
+	session.id = s.id.Next()
+	if err := s.write(tx, session); err != nil {
+		session.id = old
+		return fmt.Errorf("SQLStore.Regenerate(): %w", err)
+	}
+	if _, err := tx.Exec(bind(s.dialect, "DELETE FROM "+sessionTable+" WHERE id = ?"), old); err != nil {
+		session.id = old
+		return fmt.Errorf("SQLStore.Regenerate(): %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		session.id = old
+		return fmt.Errorf("SQLStore.Regenerate(): %w", err)
+	}
+	return nil
+}
+
+// Delete expired sessions from the store.
+func (s *SQLStore) Cleanup() {
+	_, err := s.db.Exec(bind(s.dialect, "DELETE FROM "+sessionTable+" WHERE expires <= ?"), time.Now().UnixMilli())
+	if err != nil {
+		slog.Warn("failed to delete expired sessions", slog.Any("err", err))
+	}
+}
+
+// Executes statements, a *sql.DB or *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// Insert or replace a session row.
+func (s *SQLStore) write(db execer, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(
+		s.dialect.Upsert(sessionTable, "id", sessionColumns),
+		session.id,
+		session.UserID,
+		session.Expires.UnixMilli(),
+		string(data),
+		session.version,
+	)
+	return err
+}
+
+// Save a session row if its stored version is still expected.
+// Returns false if the row was not updated.
+func (s *SQLStore) update(session *Session, expected int64) (bool, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return false, err
+	}
+	result, err := s.db.Exec(
+		bind(s.dialect, "UPDATE "+sessionTable+" SET user_id = ?, expires = ?, data = ?, version = ? WHERE id = ? AND version = ?"),
+		session.UserID,
+		session.Expires.UnixMilli(),
+		string(data),
+		session.version,
+		session.id,
+		expected,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,247 @@
+package sessions
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDialect(t *testing.T) {
+	type Test struct {
+		dialect Dialect
+		bind    string
+		upsert  string
+	}
+
+	tests := []Test{
+		{
+			SQLite,
+			"SELECT data FROM sessions WHERE user_id = ? AND expires > ?",
+			"INSERT INTO sessions (id, user_id, expires, data, version) VALUES (?, ?, ?, ?, ?) ON CONFLICT (id) DO UPDATE SET user_id = excluded.user_id, expires = excluded.expires, data = excluded.data, version = excluded.version",
+		},
+		{
+			Postgres,
+			"SELECT data FROM sessions WHERE user_id = $1 AND expires > $2",
+			"INSERT INTO sessions (id, user_id, expires, data, version) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (id) DO UPDATE SET user_id = excluded.user_id, expires = excluded.expires, data = excluded.data, version = excluded.version",
+		},
+	}
+
+	for _, test := range tests {
+		if actual := bind(test.dialect, "SELECT data FROM sessions WHERE user_id = ? AND expires > ?"); actual != test.bind {
+			t.Errorf("bind() = %q; expect %q", actual, test.bind)
+		}
+		if actual := test.dialect.Upsert(sessionTable, "id", sessionColumns); actual != test.upsert {
+			t.Errorf("Dialect.Upsert() = %q; expect %q", actual, test.upsert)
+		}
+	}
+}
+
+func TestSQLStore(t *testing.T) {
+	testStore(t, func(t *testing.T, options ...StoreOption) SessionStore {
+		return newTestSQLStore(t, options...)
+	})
+}
+
+func TestSQLStore_ReadByUser(t *testing.T) {
+	testReadByUser(t, newTestSQLStore(t))
+}
+
+func TestSQLStore_ReadWrites(t *testing.T) {
+	type Test struct {
+		name        string
+		options     []StoreOption
+		expectWrite bool
+	}
+
+	tests := []Test{
+		{name: "Read() should leave the row alone without an idle timeout", expectWrite: false},
+		{name: "Read() should save the access time with an idle timeout", options: []StoreOption{WithIdleTimeout(time.Hour)}, expectWrite: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			database := &fakeDriver{rows: map[string][]driver.Value{}}
+			db := sql.OpenDB(database)
+			t.Cleanup(func() { db.Close() })
+			store := NewSQLStore(db, Postgres, test.options...)
+			if err := store.Migrate(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			session, err := store.Create()
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := slices.Clone(database.rows[session.ID()])
+			if _, err := store.Read(session.ID()); err != nil {
+				t.Fatal(err)
+			}
+			after := database.rows[session.ID()]
+			if written := before[3] != after[3]; written != test.expectWrite {
+				t.Errorf("SQLStore.Read() wrote the row = %v; expect %v", written, test.expectWrite)
+			}
+			if after[4] != before[4] {
+				t.Errorf("SQLStore.Read() version = %v; expect %v", after[4], before[4])
+			}
+		})
+	}
+}
+
+// Test ReadByUser() and Cleanup() against a SQLStore, using the fake or a real database.
+func testReadByUser(t *testing.T, store *SQLStore) {
+	for _, userID := range []string{"u1", "u1", "u2"} {
+		session, err := store.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		session.UserID = userID
+		if err := store.Update(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expired, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.UserID = "u1"
+	expired.Expires = time.Now().Add(-time.Minute)
+	if err := store.Update(expired); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := store.ReadByUser("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Errorf("SQLStore.ReadByUser() returned %d sessions; expect 2", len(sessions))
+	}
+
+	// Updating a session deleted by Cleanup() fails, updating a kept one succeeds.
+	store.Cleanup()
+	expired.Expires = time.Now().Add(time.Hour)
+	if err := store.Update(expired); err == nil {
+		t.Errorf("SQLStore.Cleanup() kept an expired session")
+	}
+	for _, session := range sessions {
+		if err := store.Update(session); err != nil {
+			t.Errorf("SQLStore.Update() error = %v; expect Cleanup() to keep unexpired sessions", err)
+		}
+	}
+}
+
+// Create a SQLStore on a fake database and migrate it.
+func newTestSQLStore(t *testing.T, options ...StoreOption) *SQLStore {
+	db := sql.OpenDB(&fakeDriver{rows: map[string][]driver.Value{}})
+	t.Cleanup(func() { db.Close() })
+	store := NewSQLStore(db, Postgres, options...)
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// Minimal in-memory database understanding only the statements SQLStore executes.
+type fakeDriver struct {
+	rows    map[string][]driver.Value // Session rows by ID, in sessionColumns order.
+	version int64
+	mutex   sync.Mutex
+}
+
+func (d *fakeDriver) Connect(ctx context.Context) (driver.Conn, error) { return d, nil }
+func (d *fakeDriver) Driver() driver.Driver                            { return d }
+func (d *fakeDriver) Open(name string) (driver.Conn, error)            { return d, nil }
+func (d *fakeDriver) Prepare(query string) (driver.Stmt, error)        { return &fakeStmt{d, query}, nil }
+func (d *fakeDriver) Close() error                                     { return nil }
+func (d *fakeDriver) Begin() (driver.Tx, error)                        { return d, nil }
+func (d *fakeDriver) Commit() error                                    { return nil }
+func (d *fakeDriver) Rollback() error                                  { return nil }
+
+type fakeStmt struct {
+	db    *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	switch {
+	case strings.HasPrefix(s.query, "CREATE"), strings.HasPrefix(s.query, "ALTER"):
+	case strings.HasPrefix(s.query, "INSERT INTO "+migrationTable):
+		s.db.version = args[0].(int64)
+	case strings.HasPrefix(s.query, "INSERT INTO "+sessionTable):
+		s.db.rows[args[0].(string)] = args
+	case strings.HasPrefix(s.query, "UPDATE "+sessionTable):
+		row, ok := s.db.rows[args[4].(string)]
+		if !ok || row[4] != args[5] {
+			return driver.RowsAffected(0), nil
+		}
+		s.db.rows[args[4].(string)] = []driver.Value{args[4], args[0], args[1], args[2], args[3]}
+	case strings.HasPrefix(s.query, "DELETE FROM sessions WHERE id"):
+		delete(s.db.rows, args[0].(string))
+	case strings.HasPrefix(s.query, "DELETE FROM sessions WHERE expires"):
+		for id, row := range s.db.rows {
+			if row[2].(int64) <= args[0].(int64) {
+				delete(s.db.rows, id)
+			}
+		}
+	default:
+		return nil, errors.New("unexpected statement: " + s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+
+	var values [][]driver.Value
+	switch {
+	case strings.HasPrefix(s.query, "SELECT COALESCE"):
+		values = append(values, []driver.Value{s.db.version})
+	case strings.HasPrefix(s.query, "SELECT data FROM sessions WHERE id"):
+		if row, ok := s.db.rows[args[0].(string)]; ok {
+			values = append(values, []driver.Value{row[3]})
+		}
+	case strings.HasPrefix(s.query, "SELECT version FROM sessions WHERE id"):
+		if row, ok := s.db.rows[args[0].(string)]; ok {
+			values = append(values, []driver.Value{row[4]})
+		}
+	case strings.HasPrefix(s.query, "SELECT data FROM sessions WHERE user_id"):
+		for _, row := range s.db.rows {
+			if row[1] == args[0] && row[2].(int64) > args[1].(int64) {
+				values = append(values, []driver.Value{row[3]})
+			}
+		}
+	default:
+		return nil, errors.New("unexpected query: " + s.query)
+	}
+	return &fakeRows{values: values}, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"value"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
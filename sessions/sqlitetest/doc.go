@@ -0,0 +1,4 @@
+// Package sqlitetest tests sessions.SQLStore against a real SQLite database.
+// It is a separate module so the cgo SQLite driver is not a requirement of core.
+// Run with: cd sessions/sqlitetest && go test ./...
+package sqlitetest
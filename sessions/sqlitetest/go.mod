@@ -0,0 +1,10 @@
+module github.com/jrrdcnnlly/core/sessions/sqlitetest
+
+go 1.25.1
+
+require (
+	github.com/jrrdcnnlly/core v0.0.0
+	github.com/mattn/go-sqlite3 v1.14.33
+)
+
+replace github.com/jrrdcnnlly/core => ../..
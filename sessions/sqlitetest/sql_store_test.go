@@ -0,0 +1,129 @@
+package sqlitetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jrrdcnnlly/core/sessions"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLStore(t *testing.T) {
+	store := newSQLiteSQLStore(t)
+	session, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.UserID = "u1"
+	session.Set("theme", "dark")
+	if err := store.Update(session); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := store.Read(session.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.UserID != "u1" {
+		t.Errorf("Read().UserID = %q; expect %q", read.UserID, "u1")
+	}
+	if theme, ok := sessions.Get[string](read, "theme"); !ok || theme != "dark" {
+		t.Errorf("Get[string]() = %q, %v; expect %q, true", theme, ok, "dark")
+	}
+
+	// The session has been updated since it was read.
+	session.Set("theme", "light")
+	if err := store.Update(session); err != nil {
+		t.Fatal(err)
+	}
+	read.Set("theme", "blue")
+	if err := store.Update(read); !errors.Is(err, sessions.ErrConflict) {
+		t.Errorf("Update() error = %v; expect %v", err, sessions.ErrConflict)
+	}
+
+	old := session.ID()
+	if err := store.Regenerate(session); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Read(old); err == nil {
+		t.Errorf("Read() error = nil; expect the old ID to be deleted")
+	}
+	if err := store.Delete(session.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Read(session.ID()); err == nil {
+		t.Errorf("Read() error = nil; expect an error for a deleted session")
+	}
+}
+
+func TestSQLStore_ReadByUser(t *testing.T) {
+	store := newSQLiteSQLStore(t)
+	for _, userID := range []string{"u1", "u1", "u2"} {
+		session, err := store.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		session.UserID = userID
+		if err := store.Update(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expired, err := store.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.UserID = "u1"
+	expired.Expires = time.Now().Add(-time.Minute)
+	if err := store.Update(expired); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := store.ReadByUser("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 {
+		t.Errorf("SQLStore.ReadByUser() returned %d sessions; expect 2", len(read))
+	}
+
+	// Updating a session deleted by Cleanup() fails, updating a kept one succeeds.
+	store.Cleanup()
+	expired.Expires = time.Now().Add(time.Hour)
+	if err := store.Update(expired); err == nil {
+		t.Errorf("SQLStore.Cleanup() kept an expired session")
+	}
+	for _, session := range read {
+		if err := store.Update(session); err != nil {
+			t.Errorf("SQLStore.Update() error = %v; expect Cleanup() to keep unexpired sessions", err)
+		}
+	}
+}
+
+func TestSQLStore_Migrate(t *testing.T) {
+	store := newSQLiteSQLStore(t)
+
+	// Migrating an up to date database is a no-op.
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Errorf("SQLStore.Migrate() error = %v; expect migrations to apply once", err)
+	}
+}
+
+// Create a SQLStore on a SQLite database in a temporary directory and migrate it.
+func newSQLiteSQLStore(t *testing.T, options ...sessions.StoreOption) *sessions.SQLStore {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "sessions.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SQLite allows one writer, a single connection avoids "database is locked" errors.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	store := sessions.NewSQLStore(db, sessions.SQLite, options...)
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
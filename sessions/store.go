@@ -1,12 +1,47 @@
 package sessions
 
-import "net/http"
+import "errors"
+
+// Returned by SessionStore.Update() when the session has been updated by another request
+// since it was read.
+var ErrConflict = errors.New("session update conflict")
 
 // Define an interface for session caches.
 type SessionStore interface {
 	Create() (*Session, error)
 	Read(id string) (*Session, error)
+	// Save the session and increment its version.
+	// Fails with ErrConflict if the stored version no longer matches the session's.
 	Update(session *Session) error
 	Delete(id string) error
-	FromRequest(r *http.Request) (*Session, error)
+	// Atomically move the session to a new ID and delete the old ID.
+	// Use after a change in privilege, such as logging in, to prevent session fixation.
+	// Handlers behind the session middleware call Session.Regenerate() instead.
+	Regenerate(session *Session) error
+}
+
+// Optional interface for stores that keep the session in the cookie itself, such as CookieStore.
+// The session middleware sets the cookie to the encoded session rather than its ID,
+// and decodes the cookie value rather than reading the session by ID.
+type CookieEncoder interface {
+	// Return the session cookie value carrying the session.
+	Encode(session *Session) (string, error)
+	// Return the session carried by a session cookie value.
+	Decode(value string) (*Session, error)
+}
+
+// Return the session cookie value for a session, its ID unless the store is a CookieEncoder.
+func cookieValue(store SessionStore, session *Session) (string, error) {
+	if encoder, ok := store.(CookieEncoder); ok {
+		return encoder.Encode(session)
+	}
+	return session.id, nil
+}
+
+// Return the session named or carried by a session cookie value.
+func cookieSession(store SessionStore, value string) (*Session, error) {
+	if encoder, ok := store.(CookieEncoder); ok {
+		return encoder.Decode(value)
+	}
+	return store.Read(value)
 }
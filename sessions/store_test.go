@@ -0,0 +1,153 @@
+package sessions
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Check the behavior shared by every SessionStore implementation.
+// newStore must return an empty store configured with options.
+func testStore(t *testing.T, newStore func(t *testing.T, options ...StoreOption) SessionStore) {
+	t.Run("Created sessions should be readable", func(t *testing.T) {
+		store := newStore(t)
+		session, err := store.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := store.Read(session.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.ID() != session.ID() {
+			t.Errorf("Read().ID() = %q; expect %q", read.ID(), session.ID())
+		}
+		if !read.Created.Equal(session.Created) {
+			t.Errorf("Read().Created = %v; expect %v", read.Created, session.Created)
+		}
+	})
+
+	t.Run("Unknown sessions should not be readable", func(t *testing.T) {
+		store := newStore(t)
+		if _, err := store.Read("missing"); err == nil {
+			t.Errorf("Read() error = nil; expect an error for an unknown ID")
+		}
+	})
+
+	t.Run("Updated sessions should keep their data", func(t *testing.T) {
+		store := newStore(t)
+		session, err := store.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		session.UserID = "u1"
+		session.Set("cart", cart{Items: []string{"apple"}, Total: 1.5})
+		if err := store.Update(session); err != nil {
+			t.Fatal(err)
+		}
+		read, err := store.Read(session.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.UserID != "u1" {
+			t.Errorf("Read().UserID = %q; expect %q", read.UserID, "u1")
+		}
+		if value, ok := Get[cart](read, "cart"); !ok || value.Total != 1.5 {
+			t.Errorf("Get[cart]() = %v, %v; expect the updated cart", value, ok)
+		}
+	})
+
+	t.Run("Stale updates should conflict", func(t *testing.T) {
+		store := newStore(t)
+		session, err := store.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		first, err := store.Read(session.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := store.Read(session.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		first.Set("theme", "dark")
+		if err := store.Update(first); err != nil {
+			t.Fatal(err)
+		}
+		if first.Version() != session.Version()+1 {
+			t.Errorf("Version() = %d; expect %d after Update()", first.Version(), session.Version()+1)
+		}
+		second.Set("theme", "light")
+		if err := store.Update(second); !errors.Is(err, ErrConflict) {
+			t.Errorf("Update() error = %v; expect %v", err, ErrConflict)
+		}
+		read, err := store.Read(session.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if theme, _ := Get[string](read, "theme"); theme != "dark" {
+			t.Errorf("Get[string]() = %q; expect the first update to be kept", theme)
+		}
+	})
+
+	t.Run("Deleted sessions should not be readable", func(t *testing.T) {
+		store := newStore(t)
+		session, err := store.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Delete(session.ID()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Read(session.ID()); err == nil {
+			t.Errorf("Read() error = nil; expect an error for a deleted session")
+		}
+		if err := store.Delete(session.ID()); err != nil {
+			t.Errorf("Delete() error = %v; expect nil for a missing session", err)
+		}
+	})
+
+	t.Run("Regenerated sessions should move to a new ID", func(t *testing.T) {
+		store := newStore(t)
+		session, err := store.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		old := session.ID()
+		session.Set("visited", true)
+		if err := store.Regenerate(session); err != nil {
+			t.Fatal(err)
+		}
+		if session.ID() == old {
+			t.Fatalf("ID() = %q; expect a new ID", old)
+		}
+		if _, err := store.Read(old); err == nil {
+			t.Errorf("Read() error = nil; expect the old ID to be deleted")
+		}
+		read, err := store.Read(session.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if visited, _ := Get[bool](read, "visited"); !visited {
+			t.Errorf("Get[bool]() = false; expect the data to move to the new ID")
+		}
+	})
+
+	t.Run("Expired sessions should not be readable", func(t *testing.T) {
+		store := newStore(t, WithIdleTimeout(time.Hour))
+		session, err := store.Create()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Simulate two idle hours, past the one hour idle timeout.
+		session.Accessed = session.Accessed.Add(-2 * time.Hour)
+		session.Expires = session.Expires.Add(-2 * time.Hour)
+		if err := store.Update(session); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Read(session.ID()); err == nil {
+			t.Errorf("Read() error = nil; expect an idle session to have expired")
+		}
+	})
+}